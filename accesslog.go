@@ -0,0 +1,182 @@
+package algnhsa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// AccessLogEntry is an access log record.
+type AccessLogEntry struct {
+	RequestID          string
+	LambdaRequestID    string
+	RequestType        string
+	Stage              string
+	RequestTime        time.Time
+	Method             string
+	Path               string
+	Status             int
+	ResponseLength     int
+	IntegrationLatency time.Duration
+	SourceIP           string
+	UserAgent          string
+	Principal          string
+}
+
+type accessLogField struct {
+	name  string
+	value func(entry *AccessLogEntry) interface{}
+}
+
+// accessLogFields are the JSON fields of the access log records.
+// The field names follow the API Gateway $context variable names,
+// except requestType and lambdaRequestId, which have no $context equivalent.
+var accessLogFields = []accessLogField{
+	{"requestId", func(e *AccessLogEntry) interface{} { return e.RequestID }},
+	{"lambdaRequestId", func(e *AccessLogEntry) interface{} { return e.LambdaRequestID }},
+	{"requestType", func(e *AccessLogEntry) interface{} { return e.RequestType }},
+	{"stage", func(e *AccessLogEntry) interface{} { return e.Stage }},
+	{"requestTimeEpoch", func(e *AccessLogEntry) interface{} {
+		if e.RequestTime.IsZero() {
+			return 0
+		}
+		return e.RequestTime.UnixMilli()
+	}},
+	{"httpMethod", func(e *AccessLogEntry) interface{} { return e.Method }},
+	{"path", func(e *AccessLogEntry) interface{} { return e.Path }},
+	{"status", func(e *AccessLogEntry) interface{} { return e.Status }},
+	{"responseLength", func(e *AccessLogEntry) interface{} { return e.ResponseLength }},
+	{"integrationLatency", func(e *AccessLogEntry) interface{} { return e.IntegrationLatency.Milliseconds() }},
+	{"sourceIp", func(e *AccessLogEntry) interface{} { return e.SourceIP }},
+	{"userAgent", func(e *AccessLogEntry) interface{} { return e.UserAgent }},
+	{"principalId", func(e *AccessLogEntry) interface{} { return e.Principal }},
+}
+
+// AccessLog writes an access log record for every request.
+// By default, records are written as JSON objects, one per line.
+type AccessLog struct {
+	// Template formats the record, it's executed with an AccessLogEntry.
+	// A newline is appended to the output.
+	// For example: {{.SourceIP}} {{.Principal}} [{{.RequestTime.Format "02/Jan/2006:15:04:05 -0700"}}] "{{.Method}} {{.Path}}" {{.Status}} {{.ResponseLength}}
+	Template *template.Template
+
+	// Fields are the JSON fields written when Template is not set, in the given order.
+	// Valid field names are requestId, lambdaRequestId, requestType, stage, requestTimeEpoch, httpMethod, path,
+	// status, responseLength, integrationLatency, sourceIp, userAgent and principalId.
+	// By default, all fields are written. New panics if a field name is unknown.
+	Fields []string
+
+	// Writer is where the records are written to.
+	// By default, os.Stdout is used.
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+// validate checks the field names.
+func (l *AccessLog) validate() error {
+	for _, name := range l.Fields {
+		if _, ok := accessLogFieldByName(name); !ok {
+			return fmt.Errorf("unknown access log field %q", name)
+		}
+	}
+	return nil
+}
+
+func accessLogFieldByName(name string) (accessLogField, bool) {
+	i := slices.IndexFunc(accessLogFields, func(field accessLogField) bool { return field.name == name })
+	if i < 0 {
+		return accessLogField{}, false
+	}
+	return accessLogFields[i], true
+}
+
+// fields returns the fields to write in the configured order.
+func (l *AccessLog) fields() []accessLogField {
+	if len(l.Fields) == 0 {
+		return accessLogFields
+	}
+	fields := make([]accessLogField, 0, len(l.Fields))
+	for _, name := range l.Fields {
+		if field, ok := accessLogFieldByName(name); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func newAccessLogEntry(event lambdaRequest, resp LambdaResponse, start time.Time) *AccessLogEntry {
+	entry := &AccessLogEntry{
+		RequestType:        event.requestType.String(),
+		Method:             event.HTTPMethod,
+		Path:               event.Path,
+		Status:             resp.StatusCode,
+		ResponseLength:     resp.bodySize(),
+		IntegrationLatency: time.Since(start),
+		SourceIP:           event.SourceIP,
+		Principal:          authorizerPrincipal(event.Context),
+	}
+	if rc, ok := RequestContextFromContext(event.Context); ok {
+		entry.RequestID = rc.RequestID
+		entry.Stage = rc.Stage
+		entry.RequestTime = rc.RequestTime
+		entry.UserAgent = rc.UserAgent
+	}
+	if inv, ok := InvocationFromContext(event.Context); ok {
+		entry.LambdaRequestID = inv.LambdaRequestID()
+	}
+	return entry
+}
+
+func (l *AccessLog) writeJSON(buf *bytes.Buffer, entry *AccessLogEntry) error {
+	buf.WriteByte('{')
+	first := true
+	for _, field := range l.fields() {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(field.name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(field.value(entry))
+		if err != nil {
+			return err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// write writes the access log record for a request.
+func (l *AccessLog) write(event lambdaRequest, resp LambdaResponse, start time.Time) error {
+	entry := newAccessLogEntry(event, resp, start)
+
+	var buf bytes.Buffer
+	var err error
+	if l.Template != nil {
+		err = l.Template.Execute(&buf, entry)
+	} else {
+		err = l.writeJSON(&buf, entry)
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+
+	w := l.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = w.Write(buf.Bytes())
+	return err
+}
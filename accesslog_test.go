@@ -0,0 +1,77 @@
+package algnhsa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"text/template"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func accessLogHandler(accessLog *AccessLog) lambdaHandler {
+	return lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "Hello from Lambda!")
+		}),
+		opts: &Options{AccessLog: accessLog},
+	}
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.RequestContext.Authorizer.JWT.Claims["sub"] = "user"
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	var buf bytes.Buffer
+	lh := accessLogHandler(&AccessLog{Writer: &buf})
+	_, err = lh.Invoke(context.Background(), payload)
+	asrt.NoError(err)
+
+	var record map[string]interface{}
+	asrt.NoError(json.Unmarshal(buf.Bytes(), &record))
+	asrt.Equal("id", record["requestId"])
+	asrt.Equal("APIGatewayV2", record["requestType"])
+	asrt.Equal("$default", record["stage"])
+	asrt.Equal(float64(1583348638390), record["requestTimeEpoch"])
+	asrt.Equal("POST", record["httpMethod"])
+	asrt.Equal("/my/path", record["path"])
+	asrt.Equal(float64(200), record["status"])
+	asrt.Equal(float64(18), record["responseLength"])
+	asrt.Equal("IP", record["sourceIp"])
+	asrt.Equal("agent", record["userAgent"])
+	asrt.Equal("user", record["principalId"])
+	asrt.Contains(record, "integrationLatency")
+}
+
+func TestAccessLogFields(t *testing.T) {
+	var buf bytes.Buffer
+	lh := accessLogHandler(&AccessLog{Writer: &buf, Fields: []string{"status", "httpMethod"}})
+	_, err := lh.Invoke(context.Background(), []byte(apiGatewayV1TestEvent))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":200,"httpMethod":"GET"}`+"\n", buf.String())
+}
+
+func TestAccessLogTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	tmpl := template.Must(template.New("").Parse(`{{.SourceIP}} "{{.Method}} {{.Path}}" {{.Status}} {{.ResponseLength}}`))
+	lh := accessLogHandler(&AccessLog{Writer: &buf, Template: tmpl})
+	_, err := lh.Invoke(context.Background(), []byte(albTestEvent))
+	assert.NoError(t, err)
+	assert.Equal(t, `72.12.164.125 "GET /lambda" 200 18`+"\n", buf.String())
+}
+
+func TestAccessLogUnknownField(t *testing.T) {
+	opts := &Options{AccessLog: &AccessLog{Fields: []string{"status", "httpStatus"}}}
+	assert.PanicsWithValue(t, `algnhsa: unknown access log field "httpStatus"`, func() {
+		New(nil, opts)
+	})
+}
@@ -2,10 +2,10 @@ package algnhsa
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/http/httptest"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 )
@@ -31,35 +31,144 @@ type lambdaHandler struct {
 }
 
 func (handler lambdaHandler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
-	resp, err := handler.handleEvent(ctx, payload)
+	start := time.Now()
+	tracer := handler.opts.tracer()
+	ctx, span := tracer.Start(ctx, SpanNameInvoke, SpanKindServer)
+	defer span.End()
+	eventReq, resp, err := handler.handleEvent(ctx, span, payload)
 	if err != nil {
+		var invErr *InvocationError
+		if errors.As(err, &invErr) {
+			handler.record(eventReq, resp, start)
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+	if err := handler.opts.Hooks.beforeMarshal(eventReq.Context, &resp); err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 	if handler.opts.DebugLog {
+		resp.encodeBody()
 		fmt.Printf("Response: %+v", resp)
 	}
-	return json.Marshal(resp)
+	_, encodeSpan := tracer.Start(ctx, SpanNameEncode, SpanKindInternal)
+	data, err := marshalResponse(&resp, handler.opts)
+	endSpan(encodeSpan, err)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	handler.record(eventReq, resp, start)
+	return data, nil
+}
+
+// record emits the metrics and writes the access log of the invocation.
+func (handler lambdaHandler) record(eventReq lambdaRequest, resp LambdaResponse, start time.Time) {
+	if handler.opts.Metrics != nil {
+		if err := handler.opts.Metrics.emit(eventReq, resp, start); err != nil {
+			fmt.Printf("Failed to emit metrics: %v\n", err)
+		}
+	}
+	if handler.opts.AccessLog != nil {
+		if err := handler.opts.AccessLog.write(eventReq, resp, start); err != nil {
+			fmt.Printf("Failed to write access log: %v\n", err)
+		}
+	}
 }
 
-func (handler lambdaHandler) handleEvent(ctx context.Context, payload []byte) (lambdaResponse, error) {
+func (handler lambdaHandler) handleEvent(ctx context.Context, invokeSpan Span, payload []byte) (lambdaRequest, LambdaResponse, error) {
 	if handler.opts.DebugLog {
 		fmt.Printf("Request: %s", payload)
 	}
+	tracer := handler.opts.tracer()
+	ctx = newInvocationContext(ctx)
+
+	_, span := tracer.Start(ctx, SpanNameDecodeEvent, SpanKindInternal)
 	eventReq, err := newLambdaRequest(ctx, payload, handler.opts)
+	endSpan(span, err)
 	if err != nil {
-		return lambdaResponse{}, err
+		return eventReq, LambdaResponse{}, err
+	}
+	spanName, attrs := requestAttributes(eventReq)
+	invokeSpan.SetName(spanName)
+	invokeSpan.SetAttributes(attrs...)
+
+	if handler.opts.AllowedSources != nil {
+		rc, _ := RequestContextFromContext(eventReq.Context)
+		if !handler.opts.AllowedSources.allows(rc) {
+			return eventReq, LambdaResponse{}, ErrSourceNotAllowed
+		}
+	}
+	if handler.opts.RequestContext != nil {
+		eventReq.Context = handler.opts.RequestContext(eventReq.Context, eventReq.event)
+		if eventReq.Context == nil {
+			panic("algnhsa: RequestContext returned a nil context")
+		}
+	}
+	if err := handler.opts.Hooks.afterDecode(eventReq.Context, eventReq.event); err != nil {
+		return eventReq, LambdaResponse{}, err
 	}
-	r, err := newHTTPRequest(eventReq)
+
+	_, span = tracer.Start(ctx, SpanNameBuildRequest, SpanKindInternal)
+	r, err := newHTTPRequest(eventReq, handler.opts)
+	endSpan(span, err)
+	if err != nil {
+		return eventReq, LambdaResponse{}, err
+	}
+	propagateTrace(r, handler.opts)
+	// The secret header was removed from the event when it was decoded, the origin is verified before
+	// AfterRequest, so that it's only called for verified requests.
+	originVerified := handler.opts.OriginVerifier == nil || handler.opts.OriginVerifier.verify(eventReq.originSecrets)
+	if originVerified {
+		if err := handler.opts.Hooks.afterRequest(r); err != nil {
+			return eventReq, LambdaResponse{}, err
+		}
+	}
+
+	w := newResponseWriter()
+	setRequestIDHeaders(r.Context(), w.Header(), handler.opts)
+	handlerCtx, span := tracer.Start(r.Context(), SpanNameHandler, SpanKindInternal)
+	if !originVerified {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	} else if decompressRequestBody(w, r, handler.opts) && limitRequestBody(w, r, handler.opts) {
+		handler.httpHandler.ServeHTTP(w, r.WithContext(handlerCtx))
+		if w.requestBodyTooLarge {
+			// The response is buffered, replace it regardless of how the handler dealt with the read error.
+			w = newResponseWriter()
+			setRequestIDHeaders(r.Context(), w.Header(), handler.opts)
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		}
+	}
+	span.End()
+
+	_, span = tracer.Start(ctx, SpanNameBuildResponse, SpanKindInternal)
+	resp, err := newLambdaResponse(w, handler.opts, eventReq.requestType)
+	endSpan(span, err)
 	if err != nil {
-		return lambdaResponse{}, err
+		return eventReq, LambdaResponse{}, err
 	}
-	w := httptest.NewRecorder()
-	handler.httpHandler.ServeHTTP(w, r)
-	return newLambdaResponse(w, handler.opts, eventReq.requestType)
+	if err := handler.opts.Hooks.afterHandler(r, &resp); err != nil {
+		return eventReq, LambdaResponse{}, err
+	}
+	if err := invocationFailure(ctx, r, &resp, handler.opts); err != nil {
+		// The response is returned for metrics and access logs.
+		return eventReq, resp, err
+	}
+	invokeSpan.SetAttributes(Attribute{Key: "http.response.status_code", Value: resp.StatusCode})
+	return eventReq, resp, nil
 }
 
 // ListenAndServe starts the AWS Lambda runtime (aws-lambda-go lambda.Start) with a given handler.
+// Outside of Lambda, it serves the handler with a regular HTTP server on Options.LocalAddr instead.
 func ListenAndServe(handler http.Handler, opts *Options) {
+	if opts == nil {
+		opts = defaultOptions
+	}
+	if !runningOnLambda() {
+		listenAndServeLocal(handler, opts)
+		return
+	}
 	lambdaHandler := New(handler, opts)
-	lambda.StartWithOptions(lambdaHandler)
+	lambda.StartWithOptions(lambdaHandler, opts.lambdaOptions()...)
 }
@@ -2,7 +2,6 @@ package algnhsa
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
@@ -32,9 +31,16 @@ func getALBSourceIP(event events.ALBTargetGroupRequest) string {
 	return ""
 }
 
+type albRequestWithoutBody struct {
+	events.ALBTargetGroupRequest
+	Body skippedJSON `json:"body"`
+}
+
 func newALBRequest(ctx context.Context, payload []byte, opts *Options) (lambdaRequest, error) {
-	var event events.ALBTargetGroupRequest
-	if err := json.Unmarshal(payload, &event); err != nil {
+	event, rawBody, err := decodeEvent(payload, opts, func(e *albRequestWithoutBody) events.ALBTargetGroupRequest {
+		return e.ALBTargetGroupRequest
+	})
+	if err != nil {
 		return lambdaRequest{}, err
 	}
 	if event.RequestContext.ELB.TargetGroupArn == "" {
@@ -60,28 +66,27 @@ func newALBRequest(ctx context.Context, payload []byte, opts *Options) (lambdaRe
 		MultiValueQueryStringParameters: event.MultiValueQueryStringParameters,
 		MultiValueHeaders:               event.MultiValueHeaders,
 		Body:                            event.Body,
+		rawBody:                         rawBody,
 		IsBase64Encoded:                 event.IsBase64Encoded,
 		SourceIP:                        getALBSourceIP(event),
 		Context:                         context.WithValue(ctx, RequestTypeALB, event),
 		requestType:                     RequestTypeALB,
+		event:                           event,
 	}
 
 	return req, nil
 }
 
-func newALBResponse(r *http.Response) (lambdaResponse, error) {
-	resp := lambdaResponse{
+func newALBResponse(r *http.Response, respOpts *ResponseOptions) (LambdaResponse, error) {
+	resp := LambdaResponse{
+		StatusDescription: respOpts.StatusDescription,
 		MultiValueHeaders: r.Header,
 	}
 	return resp, nil
 }
 
 // ALBRequestFromContext extracts the ALBTargetGroupRequest event from ctx.
+// It's a shorthand for EventFromContext[events.ALBTargetGroupRequest].
 func ALBRequestFromContext(ctx context.Context) (events.ALBTargetGroupRequest, bool) {
-	val := ctx.Value(RequestTypeALB)
-	if val == nil {
-		return events.ALBTargetGroupRequest{}, false
-	}
-	event, ok := val.(events.ALBTargetGroupRequest)
-	return event, ok
+	return EventFromContext[events.ALBTargetGroupRequest](ctx)
 }
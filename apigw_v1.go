@@ -2,10 +2,10 @@ package algnhsa
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"path"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -20,9 +20,16 @@ var (
 	errAPIGatewayV1UnexpectedRequest = errors.New("expected APIGatewayProxyRequest event")
 )
 
+type apiGatewayV1RequestWithoutBody struct {
+	events.APIGatewayProxyRequest
+	Body skippedJSON `json:"body"`
+}
+
 func newAPIGatewayV1Request(ctx context.Context, payload []byte, opts *Options) (lambdaRequest, error) {
-	var event events.APIGatewayProxyRequest
-	if err := json.Unmarshal(payload, &event); err != nil {
+	event, rawBody, err := decodeEvent(payload, opts, func(e *apiGatewayV1RequestWithoutBody) events.APIGatewayProxyRequest {
+		return e.APIGatewayProxyRequest
+	})
+	if err != nil {
 		return lambdaRequest{}, err
 	}
 	if event.RequestContext.AccountID == "" {
@@ -36,11 +43,14 @@ func newAPIGatewayV1Request(ctx context.Context, payload []byte, opts *Options)
 		MultiValueQueryStringParameters: event.MultiValueQueryStringParameters,
 		Headers:                         event.Headers,
 		MultiValueHeaders:               event.MultiValueHeaders,
+		PathParameters:                  event.PathParameters,
 		Body:                            event.Body,
+		rawBody:                         rawBody,
 		IsBase64Encoded:                 event.IsBase64Encoded,
 		SourceIP:                        event.RequestContext.Identity.SourceIP,
 		Context:                         context.WithValue(ctx, RequestTypeAPIGatewayV1, event),
 		requestType:                     RequestTypeAPIGatewayV1,
+		event:                           event,
 	}
 
 	if opts.UseProxyPath {
@@ -50,19 +60,29 @@ func newAPIGatewayV1Request(ctx context.Context, payload []byte, opts *Options)
 	return req, nil
 }
 
-func newAPIGatewayV1Response(r *http.Response) (lambdaResponse, error) {
-	resp := lambdaResponse{
+func newAPIGatewayV1Response(r *http.Response, respOpts *ResponseOptions) (LambdaResponse, error) {
+	if respOpts.SingleValueHeaders {
+		resp := LambdaResponse{
+			Headers: make(map[string]string, len(r.Header)),
+		}
+		for key, values := range r.Header {
+			if key == canonicalSetCookieHeaderKey {
+				// Cookies can't be joined with a comma, API Gateway merges multiValueHeaders into headers.
+				resp.MultiValueHeaders = map[string][]string{key: values}
+				continue
+			}
+			resp.Headers[key] = strings.Join(values, ",")
+		}
+		return resp, nil
+	}
+	resp := LambdaResponse{
 		MultiValueHeaders: r.Header,
 	}
 	return resp, nil
 }
 
 // APIGatewayV1RequestFromContext extracts the APIGatewayProxyRequest event from ctx.
+// It's a shorthand for EventFromContext[events.APIGatewayProxyRequest].
 func APIGatewayV1RequestFromContext(ctx context.Context) (events.APIGatewayProxyRequest, bool) {
-	val := ctx.Value(RequestTypeAPIGatewayV1)
-	if val == nil {
-		return events.APIGatewayProxyRequest{}, false
-	}
-	event, ok := val.(events.APIGatewayProxyRequest)
-	return event, ok
+	return EventFromContext[events.APIGatewayProxyRequest](ctx)
 }
@@ -256,7 +256,7 @@ func testBodyResponseAll(t *testing.T, event string) {
 	responseBytes, err := lh.Invoke(context.Background(), []byte(event))
 	asrt.NoError(err)
 
-	var r lambdaResponse
+	var r LambdaResponse
 	err = json.Unmarshal(responseBytes, &r)
 	asrt.NoError(err)
 	asrt.Equal(200, r.StatusCode)
@@ -333,7 +333,7 @@ func testBodyContentEncodingResponseAll(t *testing.T, event string) {
 	responseBytes, err := lh.Invoke(context.Background(), []byte(event))
 	asrt.NoError(err)
 
-	var r lambdaResponse
+	var r LambdaResponse
 	err = json.Unmarshal(responseBytes, &r)
 	asrt.NoError(err)
 	asrt.Equal(200, r.StatusCode)
@@ -394,3 +394,29 @@ func TestAPIGatewayV1Base64BodyContentEncodingResponseNoMatch(t *testing.T) {
 func TestAPIGatewayV1Base64BodyContentEncodingResponseMatch(t *testing.T) {
 	testBase64BodyContentEncodingResponseMatch(t, apiGatewayV1TestEvent)
 }
+
+func TestAPIGatewayV1PathValues(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayProxyRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV1TestEvent), &event))
+	event.Resource = "/orders/{id}"
+	event.PathParameters = map[string]string{"id": "42"}
+	encodedEvent, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		route, _ := RouteFromContext(r.Context())
+		io.WriteString(w, route+" "+r.PathValue("id"))
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{SetPathValues: true},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), encodedEvent)
+	asrt.NoError(err)
+
+	var r events.APIGatewayProxyResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal("/orders/{id} 42", r.Body)
+}
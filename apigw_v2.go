@@ -2,7 +2,6 @@ package algnhsa
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"path"
@@ -22,9 +21,16 @@ var (
 	errAPIGatewayV2UnexpectedRequest = errors.New("expected APIGatewayV2HTTPRequest event")
 )
 
+type apiGatewayV2RequestWithoutBody struct {
+	events.APIGatewayV2HTTPRequest
+	Body skippedJSON `json:"body"`
+}
+
 func newAPIGatewayV2Request(ctx context.Context, payload []byte, opts *Options) (lambdaRequest, error) {
-	var event events.APIGatewayV2HTTPRequest
-	if err := json.Unmarshal(payload, &event); err != nil {
+	event, rawBody, err := decodeEvent(payload, opts, func(e *apiGatewayV2RequestWithoutBody) events.APIGatewayV2HTTPRequest {
+		return e.APIGatewayV2HTTPRequest
+	})
+	if err != nil {
 		return lambdaRequest{}, err
 	}
 	if event.Version != "2.0" {
@@ -36,11 +42,14 @@ func newAPIGatewayV2Request(ctx context.Context, payload []byte, opts *Options)
 		Path:            event.RawPath,
 		RawQueryString:  event.RawQueryString,
 		Headers:         event.Headers,
+		PathParameters:  event.PathParameters,
 		Body:            event.Body,
+		rawBody:         rawBody,
 		IsBase64Encoded: event.IsBase64Encoded,
 		SourceIP:        event.RequestContext.HTTP.SourceIP,
 		Context:         context.WithValue(ctx, RequestTypeAPIGatewayV2, event),
 		requestType:     RequestTypeAPIGatewayV2,
+		event:           event,
 	}
 
 	// APIGatewayV2 doesn't support multi-value headers.
@@ -57,13 +66,15 @@ func newAPIGatewayV2Request(ctx context.Context, payload []byte, opts *Options)
 
 	if opts.UseProxyPath {
 		req.Path = path.Join("/", event.PathParameters["proxy"])
+	} else if opts.StripStage && isDefaultEndpoint(event.RequestContext.DomainPrefix, event.RequestContext.APIID) {
+		req.Path = stripStage(req.Path, event.RequestContext.Stage)
 	}
 
 	return req, nil
 }
 
-func newAPIGatewayV2Response(r *http.Response) (lambdaResponse, error) {
-	resp := lambdaResponse{
+func newAPIGatewayV2Response(r *http.Response) (LambdaResponse, error) {
+	resp := LambdaResponse{
 		Headers: make(map[string]string, len(r.Header)),
 	}
 	// APIGatewayV2 doesn't support multi-value headers.
@@ -80,11 +91,7 @@ func newAPIGatewayV2Response(r *http.Response) (lambdaResponse, error) {
 }
 
 // APIGatewayV2RequestFromContext extracts the APIGatewayV2HTTPRequest event from ctx.
+// It's a shorthand for EventFromContext[events.APIGatewayV2HTTPRequest].
 func APIGatewayV2RequestFromContext(ctx context.Context) (events.APIGatewayV2HTTPRequest, bool) {
-	val := ctx.Value(RequestTypeAPIGatewayV2)
-	if val == nil {
-		return events.APIGatewayV2HTTPRequest{}, false
-	}
-	event, ok := val.(events.APIGatewayV2HTTPRequest)
-	return event, ok
+	return EventFromContext[events.APIGatewayV2HTTPRequest](ctx)
 }
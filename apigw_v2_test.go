@@ -240,3 +240,91 @@ func TestAPIGatewayV2Base64BodyResponseNoMatch(t *testing.T) {
 func TestAPIGatewayV2Base64BodyResponseMatch(t *testing.T) {
 	testBase64BodyResponseMatch(t, apiGatewayV2TestEvent)
 }
+
+func TestAPIGatewayV2StripStage(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.RawPath = "/prod/my/path"
+	event.RequestContext.Stage = "prod"
+	event.RequestContext.APIID = "id"
+	encodedEvent, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	dump, err := dumpAPIGatewayV2(encodedEvent, Options{StripStage: true})
+	asrt.NoError(err)
+	asrt.Equal(expectedApiGatewayV2Dump, dump)
+
+	dump, err = dumpAPIGatewayV2(encodedEvent, Options{})
+	asrt.NoError(err)
+	expected := expectedApiGatewayV2Dump
+	expected.RequestURI = "/prod/my/path?parameter1=value1&parameter1=value2&parameter2=value"
+	expected.URL.Path = "/prod/my/path"
+	asrt.Equal(expected, dump)
+
+	// Custom domains don't include the stage in the path, a path that starts with the stage name is left as is.
+	event.RequestContext.DomainName = "api.example.com"
+	event.RequestContext.DomainPrefix = "api"
+	encodedEvent, err = json.Marshal(event)
+	asrt.NoError(err)
+	dump, err = dumpAPIGatewayV2(encodedEvent, Options{StripStage: true})
+	asrt.NoError(err)
+	asrt.Equal(expected, dump)
+}
+
+func TestStageFromContext(t *testing.T) {
+	asrt := assert.New(t)
+
+	stage, ok := StageFromContext(captureRequest(t, apiGatewayV2TestEvent, &Options{}).Context())
+	asrt.True(ok)
+	asrt.Equal("$default", stage)
+
+	v1Event := events.APIGatewayProxyRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV1TestEvent), &v1Event))
+	stage, ok = StageFromContext(captureRequest(t, apiGatewayV1TestEvent, &Options{}).Context())
+	asrt.True(ok)
+	asrt.Equal(v1Event.RequestContext.Stage, stage)
+	asrt.NotEmpty(stage)
+
+	_, ok = StageFromContext(captureRequest(t, albTestEvent, &Options{}).Context())
+	asrt.False(ok)
+	_, ok = StageFromContext(context.Background())
+	asrt.False(ok)
+}
+
+func TestStripStage(t *testing.T) {
+	asrt := assert.New(t)
+
+	asrt.Equal("/my/path", stripStage("/prod/my/path", "prod"))
+	asrt.Equal("/", stripStage("/prod", "prod"))
+	asrt.Equal("/production/my/path", stripStage("/production/my/path", "prod"))
+	asrt.Equal("/my/path", stripStage("/my/path", "$default"))
+	asrt.Equal("/my/path", stripStage("/my/path", ""))
+}
+
+func TestAPIGatewayV2PathValues(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.RouteKey = "POST /orders/{id}"
+	event.PathParameters = map[string]string{"id": "42"}
+	encodedEvent, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		route, _ := RouteFromContext(r.Context())
+		io.WriteString(w, route+" "+r.PathValue("id"))
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{SetPathValues: true},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), encodedEvent)
+	asrt.NoError(err)
+
+	var r events.APIGatewayV2HTTPResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal("POST /orders/{id} 42", r.Body)
+}
@@ -0,0 +1,219 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrNoAuthorizerContext is returned by DecodeAuthorizerContext when the request has no authorizer context.
+var ErrNoAuthorizerContext = errors.New("no authorizer context in request")
+
+// IAMCaller is the IAM identity of a request authorized with IAM.
+type IAMCaller struct {
+	AccessKey             string
+	AccountID             string
+	CallerID              string
+	UserARN               string
+	UserID                string
+	PrincipalOrgID        string
+	CognitoIdentityID     string
+	CognitoIdentityPoolID string
+}
+
+// JWTClaims returns the JWT claims of the request.
+// For API Gateway V1 the claims come from a Cognito user pool authorizer,
+// for API Gateway V2 from a JWT authorizer.
+func JWTClaims(ctx context.Context) (map[string]string, bool) {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		claims, ok := event.RequestContext.Authorizer["claims"].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m := make(map[string]string, len(claims))
+		for k, v := range claims {
+			m[k] = authorizerValueString(v)
+		}
+		return m, true
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		authorizer := event.RequestContext.Authorizer
+		if authorizer == nil || authorizer.JWT == nil {
+			return nil, false
+		}
+		return authorizer.JWT.Claims, true
+	}
+	return nil, false
+}
+
+// JWTScopes returns the OAuth scopes of the request.
+// The scopes are taken from the authorizer, or from the "scope" claim of an access token.
+func JWTScopes(ctx context.Context) []string {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		if scopes, ok := event.RequestContext.Authorizer["scopes"].([]interface{}); ok {
+			s := make([]string, 0, len(scopes))
+			for _, scope := range scopes {
+				s = append(s, authorizerValueString(scope))
+			}
+			return s
+		}
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		authorizer := event.RequestContext.Authorizer
+		if authorizer != nil && authorizer.JWT != nil && len(authorizer.JWT.Scopes) > 0 {
+			return authorizer.JWT.Scopes
+		}
+	}
+	if claims, ok := JWTClaims(ctx); ok {
+		return strings.Fields(claims["scope"])
+	}
+	return nil
+}
+
+// HasScope reports whether the request was authorized with the given OAuth scope.
+func HasScope(ctx context.Context, scope string) bool {
+	for _, s := range JWTScopes(ctx) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IAMIdentity returns the IAM identity of the request.
+// It works with API Gateway V1 and V2 IAM authorization and Lambda Function URLs with the AWS_IAM auth type.
+func IAMIdentity(ctx context.Context) (IAMCaller, bool) {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		identity := event.RequestContext.Identity
+		if identity.UserArn == "" && identity.AccessKey == "" {
+			return IAMCaller{}, false
+		}
+		return IAMCaller{
+			AccessKey:             identity.AccessKey,
+			AccountID:             identity.AccountID,
+			CallerID:              identity.Caller,
+			UserARN:               identity.UserArn,
+			UserID:                identity.User,
+			CognitoIdentityID:     identity.CognitoIdentityID,
+			CognitoIdentityPoolID: identity.CognitoIdentityPoolID,
+		}, true
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		authorizer := event.RequestContext.Authorizer
+		if authorizer == nil || authorizer.IAM == nil {
+			return IAMCaller{}, false
+		}
+		iam := authorizer.IAM
+		return IAMCaller{
+			AccessKey:             iam.AccessKey,
+			AccountID:             iam.AccountID,
+			CallerID:              iam.CallerID,
+			UserARN:               iam.UserARN,
+			UserID:                iam.UserID,
+			PrincipalOrgID:        iam.PrincipalOrgID,
+			CognitoIdentityID:     iam.CognitoIdentity.IdentityID,
+			CognitoIdentityPoolID: iam.CognitoIdentity.IdentityPoolID,
+		}, true
+	}
+	return IAMCaller{}, false
+}
+
+// lambdaAuthorizerContext returns the context returned by a Lambda authorizer.
+func lambdaAuthorizerContext(ctx context.Context) (map[string]interface{}, bool) {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		authorizer := event.RequestContext.Authorizer
+		return authorizer, len(authorizer) > 0
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		authorizer := event.RequestContext.Authorizer
+		if authorizer == nil || authorizer.Lambda == nil {
+			return nil, false
+		}
+		return authorizer.Lambda, true
+	}
+	return nil, false
+}
+
+// authorizerValue looks up key in the Lambda authorizer context, then in the JWT claims.
+func authorizerValue(ctx context.Context, key string) (string, bool) {
+	if authorizer, ok := lambdaAuthorizerContext(ctx); ok {
+		if v, ok := authorizer[key]; ok && v != nil {
+			return authorizerValueString(v), true
+		}
+	}
+	if claims, ok := JWTClaims(ctx); ok {
+		if v, ok := claims[key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// setAuthorizerHeaders propagates authorizer data into the headers according to Options.AuthorizerHeaders.
+// Client-supplied headers with the mapped names are removed first to prevent spoofing.
+// When several keys map to the same header, the first key in lexical order that has a value wins.
+func setAuthorizerHeaders(ctx context.Context, headers http.Header, opts *Options) {
+	for _, name := range opts.AuthorizerHeaders {
+		headers.Del(name)
+	}
+	for _, key := range sortedKeys(opts.AuthorizerHeaders) {
+		name := http.CanonicalHeaderKey(opts.AuthorizerHeaders[key])
+		if _, ok := headers[name]; ok {
+			continue
+		}
+		if v, ok := authorizerValue(ctx, key); ok {
+			headers.Set(name, v)
+		}
+	}
+}
+
+// authorizerPrincipal returns the principal the request was authorized as:
+// the Lambda authorizer principalId, the JWT subject or the IAM user ARN.
+func authorizerPrincipal(ctx context.Context) string {
+	if authorizer, ok := lambdaAuthorizerContext(ctx); ok {
+		if v, ok := authorizer["principalId"].(string); ok && v != "" {
+			return v
+		}
+	}
+	if claims, ok := JWTClaims(ctx); ok && claims["sub"] != "" {
+		return claims["sub"]
+	}
+	if identity, ok := IAMIdentity(ctx); ok {
+		return identity.UserARN
+	}
+	return ""
+}
+
+// DecodeAuthorizerContext decodes the context returned by a Lambda authorizer into the value pointed to by v.
+// The context is decoded the same way as JSON by encoding/json.
+func DecodeAuthorizerContext(ctx context.Context, v interface{}) error {
+	authorizer, ok := lambdaAuthorizerContext(ctx)
+	if !ok {
+		return ErrNoAuthorizerContext
+	}
+	data, err := json.Marshal(authorizer)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func authorizerValueString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case []interface{}:
+		s := make([]string, len(v))
+		for i, item := range v {
+			s[i] = authorizerValueString(item)
+		}
+		return strings.Join(s, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,173 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func authorizerTestContext(t *testing.T, event interface{}) context.Context {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	assert.NoError(t, err)
+	return captureRequest(t, string(payload), &Options{}).Context()
+}
+
+func TestAuthorizerAPIGatewayV2JWT(t *testing.T) {
+	asrt := assert.New(t)
+
+	ctx := captureRequest(t, apiGatewayV2TestEvent, &Options{}).Context()
+	claims, ok := JWTClaims(ctx)
+	asrt.True(ok)
+	asrt.Equal(map[string]string{"claim1": "value1", "claim2": "value2"}, claims)
+	asrt.Equal([]string{"scope1", "scope2"}, JWTScopes(ctx))
+	asrt.True(HasScope(ctx, "scope2"))
+	asrt.False(HasScope(ctx, "scope3"))
+	_, ok = IAMIdentity(ctx)
+	asrt.False(ok)
+	asrt.ErrorIs(DecodeAuthorizerContext(ctx, &struct{}{}), ErrNoAuthorizerContext)
+}
+
+func TestAuthorizerAPIGatewayV2Lambda(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+		Lambda: map[string]interface{}{"userId": "u1", "admin": true},
+	}
+	ctx := authorizerTestContext(t, event)
+
+	var authCtx struct {
+		UserID string `json:"userId"`
+		Admin  bool   `json:"admin"`
+	}
+	asrt.NoError(DecodeAuthorizerContext(ctx, &authCtx))
+	asrt.Equal("u1", authCtx.UserID)
+	asrt.True(authCtx.Admin)
+	_, ok := JWTClaims(ctx)
+	asrt.False(ok)
+}
+
+func TestAuthorizerAPIGatewayV2IAM(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+		IAM: &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{
+			AccessKey: "AKIA",
+			AccountID: "123456789012",
+			UserARN:   "arn:aws:iam::123456789012:user/test",
+		},
+	}
+	ctx := authorizerTestContext(t, event)
+
+	identity, ok := IAMIdentity(ctx)
+	asrt.True(ok)
+	asrt.Equal(IAMCaller{
+		AccessKey: "AKIA",
+		AccountID: "123456789012",
+		UserARN:   "arn:aws:iam::123456789012:user/test",
+	}, identity)
+}
+
+func TestAuthorizerAPIGatewayV1Cognito(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayProxyRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV1TestEvent), &event))
+	event.RequestContext.Authorizer = map[string]interface{}{
+		"claims": map[string]interface{}{
+			"sub":   "user",
+			"scope": "orders:read orders:write",
+		},
+	}
+	ctx := authorizerTestContext(t, event)
+
+	claims, ok := JWTClaims(ctx)
+	asrt.True(ok)
+	asrt.Equal("user", claims["sub"])
+	asrt.True(HasScope(ctx, "orders:write"))
+	asrt.False(HasScope(ctx, "orders:delete"))
+}
+
+func TestAuthorizerAPIGatewayV1IAM(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayProxyRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV1TestEvent), &event))
+	event.RequestContext.Identity.AccessKey = "AKIA"
+	event.RequestContext.Identity.UserArn = "arn:aws:iam::123456789012:user/test"
+	ctx := authorizerTestContext(t, event)
+
+	identity, ok := IAMIdentity(ctx)
+	asrt.True(ok)
+	asrt.Equal("AKIA", identity.AccessKey)
+	asrt.Equal("arn:aws:iam::123456789012:user/test", identity.UserARN)
+}
+
+func TestAuthorizerALB(t *testing.T) {
+	ctx := captureRequest(t, albTestEvent, &Options{}).Context()
+	_, ok := JWTClaims(ctx)
+	assert.False(t, ok)
+	assert.Nil(t, JWTScopes(ctx))
+	assert.ErrorIs(t, DecodeAuthorizerContext(ctx, &struct{}{}), ErrNoAuthorizerContext)
+}
+
+func TestAuthorizerHeaders(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.Headers["x-user-id"] = "spoofed"
+	event.Headers["x-user-groups"] = "spoofed"
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	opts := &Options{AuthorizerHeaders: map[string]string{
+		"claim1": "X-User-Id",
+		"groups": "X-User-Groups",
+	}}
+	r := captureRequest(t, string(payload), opts)
+	asrt.Equal([]string{"value1"}, r.Header.Values("X-User-Id"))
+	asrt.Empty(r.Header.Values("X-User-Groups"))
+
+	event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+		Lambda: map[string]interface{}{"claim1": "u1", "groups": []interface{}{"a", "b"}},
+	}
+	payload, err = json.Marshal(event)
+	asrt.NoError(err)
+	r = captureRequest(t, string(payload), opts)
+	asrt.Equal([]string{"u1"}, r.Header.Values("X-User-Id"))
+	asrt.Equal([]string{"a,b"}, r.Header.Values("X-User-Groups"))
+}
+
+func TestAuthorizerHeadersSameHeader(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.Headers["x-user"] = "spoofed"
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	for _, tc := range []struct {
+		headers  map[string]string
+		expected []string
+	}{
+		{map[string]string{"claim1": "X-User", "missing": "X-User"}, []string{"value1"}},
+		{map[string]string{"claim1": "X-User", "claim2": "x-user"}, []string{"value1"}},
+		{map[string]string{"claim2": "X-User", "missing": "X-User"}, []string{"value2"}},
+		{map[string]string{"missing": "X-User", "other": "X-User"}, nil},
+	} {
+		// Repeat to catch map iteration order dependencies.
+		for i := 0; i < 50; i++ {
+			r := captureRequest(t, string(payload), &Options{AuthorizerHeaders: tc.headers})
+			asrt.Equal(tc.expected, r.Header.Values("X-User"), tc.headers)
+		}
+	}
+}
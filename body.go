@@ -0,0 +1,205 @@
+package algnhsa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// skippedJSON is a JSON value that is not decoded.
+type skippedJSON struct{}
+
+func (*skippedJSON) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// base64DecodedLen returns the length of the base64 encoded data with the given length and padding suffix.
+func base64DecodedLen(n int, suffix string) int {
+	decoded := base64.StdEncoding.DecodedLen(n)
+	if strings.HasSuffix(suffix, "==") {
+		return decoded - 2
+	}
+	if strings.HasSuffix(suffix, "=") {
+		return decoded - 1
+	}
+	return decoded
+}
+
+func base64StringDecodedLen(s string) int {
+	return base64DecodedLen(len(s), s[max(len(s)-2, 0):])
+}
+
+func base64BytesDecodedLen(b []byte) int {
+	return base64DecodedLen(len(b), string(b[max(len(b)-2, 0):]))
+}
+
+// newBodyReader returns a function that creates readers of the decoded request body, and the body length.
+// The body isn't copied, the readers read it from the event or directly from the Lambda payload.
+func newBodyReader(event lambdaRequest) (func() io.Reader, int64, error) {
+	var newReader func() io.Reader
+	var n int
+	if raw := event.rawBody; raw != nil && bytes.IndexByte(raw, '\\') < 0 {
+		// The body doesn't contain escape sequences, it can be read from the payload as is.
+		data := raw[1 : len(raw)-1]
+		newReader = func() io.Reader { return bytes.NewReader(data) }
+		n = len(data)
+		if event.IsBase64Encoded {
+			n = base64BytesDecodedLen(data)
+		}
+	} else {
+		body := event.Body
+		if raw != nil {
+			if err := json.Unmarshal(raw, &body); err != nil {
+				return nil, 0, err
+			}
+		}
+		newReader = func() io.Reader { return strings.NewReader(body) }
+		n = len(body)
+		if event.IsBase64Encoded {
+			n = base64StringDecodedLen(body)
+		}
+	}
+	if event.IsBase64Encoded {
+		newRawReader := newReader
+		newReader = func() io.Reader { return base64.NewDecoder(base64.StdEncoding, newRawReader()) }
+	}
+	return newReader, int64(n), nil
+}
+
+// defaultMaxDecompressedBodyBytes limits the decompressed request body size if no request body limit is set.
+const defaultMaxDecompressedBodyBytes = 8 << 20
+
+// requestBodyDecoder returns the decoder for the content encoding.
+func requestBodyDecoder(encoding string, opts *Options) func(io.Reader) (io.Reader, error) {
+	if decoder, ok := opts.RequestBodyDecoders[encoding]; ok {
+		return decoder
+	}
+	switch encoding {
+	case "gzip", "x-gzip":
+		return func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case "deflate":
+		// HTTP deflate is zlib wrapped DEFLATE.
+		return func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }
+	}
+	return nil
+}
+
+// decompressedBody is a request body decompressed while it's read.
+// Like http.MaxBytesReader, it returns *http.MaxBytesError when the decompressed body exceeds the limit.
+type decompressedBody struct {
+	r          io.Reader
+	body       io.Closer
+	remaining  int64
+	limit      int64
+	err        error
+	onExceeded func()
+}
+
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	// Read one byte more than remaining to detect bodies exceeding the limit.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.r.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		b.err = err
+		return n, err
+	}
+	n = int(b.remaining)
+	b.remaining = 0
+	b.err = &http.MaxBytesError{Limit: b.limit}
+	if b.onExceeded != nil {
+		b.onExceeded()
+	}
+	return n, b.err
+}
+
+func (b *decompressedBody) Close() error {
+	if closer, ok := b.r.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.body.Close()
+}
+
+// decompressRequestBody makes the request body decompressed according to the Content-Encoding header as it's read.
+// It responds with 400 Bad Request and returns false if the decoder can't be created, e.g. for a malformed gzip header.
+// Bodies with unsupported encodings are left as is.
+func decompressRequestBody(w http.ResponseWriter, r *http.Request, opts *Options) bool {
+	if !opts.DecompressRequestBody {
+		return true
+	}
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	decoder := requestBodyDecoder(encoding, opts)
+	if decoder == nil {
+		return true
+	}
+	limit := opts.requestBodyLimit(r.URL.Path)
+	if limit <= 0 {
+		limit = defaultMaxDecompressedBodyBytes
+	}
+	dec, err := decoder(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return false
+	}
+	body := &decompressedBody{
+		r:         dec,
+		body:      r.Body,
+		remaining: limit,
+		limit:     limit,
+	}
+	if rw, ok := w.(*responseWriter); ok {
+		body.onExceeded = func() { rw.requestBodyTooLarge = true }
+	}
+
+	// The decompressed size is unknown until the body is read.
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	r.Body = body
+	r.GetBody = nil
+	return true
+}
+
+// limitRequestBody applies the request body size limit.
+// It responds with 413 Request Entity Too Large and returns false if the body exceeds the limit.
+func limitRequestBody(w http.ResponseWriter, r *http.Request, opts *Options) bool {
+	limit := opts.requestBodyLimit(r.URL.Path)
+	if limit <= 0 {
+		return true
+	}
+	if r.ContentLength > limit {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	return true
+}
+
+// setRequestBody sets the request body, content length and GetBody.
+func setRequestBody(r *http.Request, event lambdaRequest) error {
+	newReader, n, err := newBodyReader(event)
+	if err != nil {
+		return err
+	}
+	r.ContentLength = n
+	if n == 0 {
+		r.Body = http.NoBody
+		r.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return nil
+	}
+	r.Body = io.NopCloser(newReader())
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(newReader()), nil
+	}
+	return nil
+}
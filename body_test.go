@@ -0,0 +1,200 @@
+package algnhsa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxRequestBodyBytes(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write(body)
+	}
+	opts := &Options{
+		MaxRequestBodyBytes: 10,
+		RequestBodyLimits: map[string]int64{
+			"/upload":       100,
+			"/upload/small": 5,
+		},
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        opts,
+	}
+
+	for _, tc := range []struct {
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"/my/path", "0123456789", 200},
+		{"/my/path", "0123456789a", 413},
+		{"/upload", "0123456789a", 200},
+		{"/upload/small", "012345", 413},
+		{"/upload/small", "01234", 200},
+	} {
+		event.RawPath = tc.path
+		event.Body = tc.body
+		payload, err := json.Marshal(event)
+		asrt.NoError(err)
+		responseBytes, err := lh.Invoke(context.Background(), payload)
+		asrt.NoError(err)
+		var r events.APIGatewayV2HTTPResponse
+		asrt.NoError(json.Unmarshal(responseBytes, &r))
+		asrt.Equal(tc.expectedStatus, r.StatusCode, tc.path+" "+tc.body)
+		if tc.expectedStatus == 200 {
+			asrt.Equal(tc.body, r.Body)
+		}
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	opts := &Options{RequestBodyLimits: map[string]int64{"/a": 1, "/a/b": 2}}
+	assert.Equal(t, int64(0), opts.requestBodyLimit("/"))
+	assert.Equal(t, int64(1), opts.requestBodyLimit("/a/c"))
+	assert.Equal(t, int64(2), opts.requestBodyLimit("/a/b/c"))
+}
+
+func TestDecompressRequestBody(t *testing.T) {
+	asrt := assert.New(t)
+
+	compress := func(encoding string, data string) string {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		default:
+			return base64.StdEncoding.EncodeToString([]byte(data))
+		}
+		w.Write([]byte(data))
+		w.Close()
+		return base64.StdEncoding.EncodeToString(buf.Bytes())
+	}
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.IsBase64Encoded = true
+
+	var gotHeader http.Header
+	var gotContentLength int64
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotContentLength = r.ContentLength
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}
+	opts := &Options{
+		DecompressRequestBody: true,
+		RequestBodyLimits:     map[string]int64{"/small": 5},
+		RequestBodyDecoders: map[string]func(io.Reader) (io.Reader, error){
+			"upper": func(r io.Reader) (io.Reader, error) {
+				b, err := io.ReadAll(r)
+				return strings.NewReader(strings.ToUpper(string(b))), err
+			},
+		},
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        opts,
+	}
+
+	for _, tc := range []struct {
+		path           string
+		encoding       string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"/my/path", "gzip", "hello gzip", 200, "hello gzip"},
+		{"/my/path", "deflate", "hello deflate", 200, "hello deflate"},
+		{"/my/path", "upper", "hello", 200, "HELLO"},
+		{"/my/path", "br", "not decoded", 200, "not decoded"},
+		{"/small", "gzip", "hello gzip", 413, ""},
+		{"/my/path", "gzip-invalid", "hello", 400, ""},
+	} {
+		encoding := strings.TrimSuffix(tc.encoding, "-invalid")
+		event.RawPath = tc.path
+		event.Headers = map[string]string{"content-encoding": encoding, "content-length": "1"}
+		event.Body = compress(tc.encoding, tc.body)
+		payload, err := json.Marshal(event)
+		asrt.NoError(err)
+		responseBytes, err := lh.Invoke(context.Background(), payload)
+		asrt.NoError(err)
+		var r events.APIGatewayV2HTTPResponse
+		asrt.NoError(json.Unmarshal(responseBytes, &r))
+		asrt.Equal(tc.expectedStatus, r.StatusCode, tc.encoding)
+		if tc.expectedStatus != 200 {
+			continue
+		}
+		asrt.Equal(tc.expectedBody, r.Body, tc.encoding)
+		if tc.encoding == "br" {
+			asrt.Equal("br", gotHeader.Get("Content-Encoding"))
+			continue
+		}
+		asrt.Empty(gotHeader.Get("Content-Encoding"), tc.encoding)
+		asrt.Equal(int64(-1), gotContentLength, tc.encoding)
+		asrt.Empty(gotHeader.Get("Content-Length"), tc.encoding)
+	}
+}
+
+func TestDecompressRequestBodyDefaultLimit(t *testing.T) {
+	asrt := assert.New(t)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(make([]byte, defaultMaxDecompressedBodyBytes+1))
+	zw.Close()
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.IsBase64Encoded = true
+	event.Headers = map[string]string{"content-encoding": "gzip"}
+	event.Body = base64.StdEncoding.EncodeToString(buf.Bytes())
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+
+	var readErr error
+	var n int64
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		// The handler ignores the read error, the response is replaced anyway.
+		n, readErr = io.Copy(io.Discard, r.Body)
+		w.Write([]byte("ok"))
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{DecompressRequestBody: true},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), payload)
+	asrt.NoError(err)
+	var maxBytesErr *http.MaxBytesError
+	asrt.True(errors.As(readErr, &maxBytesErr))
+	asrt.Equal(int64(defaultMaxDecompressedBodyBytes), n)
+	var r events.APIGatewayV2HTTPResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal(http.StatusRequestEntityTooLarge, r.StatusCode)
+}
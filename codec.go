@@ -0,0 +1,169 @@
+package algnhsa
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// JSONCodec encodes and decodes JSON.
+// It can be used to replace encoding/json with a faster implementation.
+// The implementation must honor json struct tags and the json.Unmarshaler interface.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// stdJSONCodec is a JSONCodec using encoding/json.
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// marshalResponse encodes the Lambda response with the JSONCodec if it's set, or with appendJSON.
+func marshalResponse(resp *LambdaResponse, opts *Options) ([]byte, error) {
+	if opts.JSONCodec != nil {
+		resp.encodeBody()
+		return opts.JSONCodec.Marshal(resp)
+	}
+	size := 256 + len(resp.Body) + base64.StdEncoding.EncodedLen(len(resp.binaryBody))
+	return resp.appendJSON(make([]byte, 0, size), !opts.DisableHTMLEscape), nil
+}
+
+// appendJSON appends the response encoded as JSON to dst.
+// The output is identical to json.Marshal, or to json.Encoder with SetEscapeHTML(false) if escapeHTML is false.
+// A binary body is base64 encoded directly into dst.
+func (resp *LambdaResponse) appendJSON(dst []byte, escapeHTML bool) []byte {
+	dst = append(dst, `{"statusCode":`...)
+	dst = strconv.AppendInt(dst, int64(resp.StatusCode), 10)
+	if resp.StatusDescription != "" {
+		dst = append(dst, `,"statusDescription":`...)
+		dst = appendJSONString(dst, resp.StatusDescription, escapeHTML)
+	}
+	if len(resp.Headers) > 0 {
+		dst = append(dst, `,"headers":{`...)
+		for i, k := range sortedKeys(resp.Headers) {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendJSONString(dst, k, escapeHTML)
+			dst = append(dst, ':')
+			dst = appendJSONString(dst, resp.Headers[k], escapeHTML)
+		}
+		dst = append(dst, '}')
+	}
+	if len(resp.MultiValueHeaders) > 0 {
+		dst = append(dst, `,"multiValueHeaders":{`...)
+		for i, k := range sortedKeys(resp.MultiValueHeaders) {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendJSONString(dst, k, escapeHTML)
+			dst = append(dst, ':')
+			dst = appendJSONStrings(dst, resp.MultiValueHeaders[k], escapeHTML)
+		}
+		dst = append(dst, '}')
+	}
+	if len(resp.Cookies) > 0 {
+		dst = append(dst, `,"cookies":`...)
+		dst = appendJSONStrings(dst, resp.Cookies, escapeHTML)
+	}
+	dst = append(dst, `,"body":`...)
+	if resp.binaryBody != nil {
+		dst = append(dst, '"')
+		dst = base64.StdEncoding.AppendEncode(dst, resp.binaryBody)
+		dst = append(dst, '"')
+	} else {
+		dst = appendJSONString(dst, resp.Body, escapeHTML)
+	}
+	if resp.IsBase64Encoded {
+		dst = append(dst, `,"isBase64Encoded":true`...)
+	}
+	return append(dst, '}')
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func appendJSONStrings(dst []byte, values []string, escapeHTML bool) []byte {
+	if values == nil {
+		return append(dst, "null"...)
+	}
+	dst = append(dst, '[')
+	for i, v := range values {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, v, escapeHTML)
+	}
+	return append(dst, ']')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s encoded as a JSON string to dst, escaping it the same way as encoding/json.
+// <, > and & are escaped if escapeHTML is true.
+func appendJSONString(dst []byte, s string, escapeHTML bool) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' && (!escapeHTML || (b != '<' && b != '>' && b != '&')) {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are escaped for JSONP compatibility.
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
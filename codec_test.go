@@ -0,0 +1,118 @@
+package algnhsa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLambdaResponseAppendJSON(t *testing.T) {
+	asrt := assert.New(t)
+	for _, resp := range []LambdaResponse{
+		{},
+		{StatusCode: 200, Body: "hello"},
+		{StatusCode: 404, StatusDescription: "404 Not Found", Body: "<html>&</html>"},
+		{
+			StatusCode:        200,
+			Headers:           map[string]string{"X-B": "b", "Content-Type": "text/plain; charset=utf-8", "X-A": "\"quoted\""},
+			MultiValueHeaders: map[string][]string{"X-Multi": {"1", "2"}, "X-Nil": nil, "X-Empty": {}},
+			Cookies:           []string{"a=b", "c=d; Path=/"},
+			Body:              "control \x00\x01\x1f \b\f\n\r\t \\ \" invalid \xff\xfe utf-8 ü 世界    \U0001F600",
+		},
+		{StatusCode: 200, Headers: map[string]string{}, Cookies: []string{}, Body: "empty"},
+		{StatusCode: 200, Body: "aGVsbG8=", IsBase64Encoded: true},
+	} {
+		expected, err := json.Marshal(resp)
+		asrt.NoError(err)
+		asrt.Equal(string(expected), string(resp.appendJSON(nil, true)))
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		asrt.NoError(enc.Encode(resp))
+		asrt.Equal(strings.TrimSuffix(buf.String(), "\n"), string(resp.appendJSON(nil, false)))
+	}
+}
+
+func TestLambdaResponseAppendJSONBinaryBody(t *testing.T) {
+	resp := LambdaResponse{StatusCode: 200, IsBase64Encoded: true, binaryBody: []byte("hello")}
+	assert.Equal(t, `{"statusCode":200,"body":"aGVsbG8=","isBase64Encoded":true}`, string(resp.appendJSON(nil, true)))
+	assert.Equal(t, 5, resp.bodySize())
+	resp.encodeBody()
+	assert.Equal(t, "aGVsbG8=", resp.Body)
+	assert.Nil(t, resp.binaryBody)
+}
+
+func TestDisableHTMLEscape(t *testing.T) {
+	asrt := assert.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<b>&</b>"))
+	})
+	data, err := New(handler, &Options{}).Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+	asrt.Contains(string(data), `"body":"\u003cb\u003e\u0026\u003c/b\u003e"`)
+
+	data, err = New(handler, &Options{DisableHTMLEscape: true}).Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+	asrt.Contains(string(data), `"body":"<b>&</b>"`)
+}
+
+type countingJSONCodec struct {
+	stdJSONCodec
+	marshal   int
+	unmarshal int
+}
+
+func (c *countingJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshal++
+	return c.stdJSONCodec.Marshal(v)
+}
+
+func (c *countingJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshal++
+	return c.stdJSONCodec.Unmarshal(data, v)
+}
+
+func TestJSONCodec(t *testing.T) {
+	asrt := assert.New(t)
+	codec := &countingJSONCodec{}
+	opts := &Options{
+		JSONCodec:          codec,
+		BinaryContentTypes: []string{"application/octet-stream"},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("hello"))
+	})
+	data, err := New(handler, opts).Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+	asrt.Equal(1, codec.unmarshal)
+	asrt.Equal(1, codec.marshal)
+
+	var resp LambdaResponse
+	asrt.NoError(json.Unmarshal(data, &resp))
+	asrt.Equal("aGVsbG8=", resp.Body)
+	asrt.True(resp.IsBase64Encoded)
+}
+
+func BenchmarkMarshalResponse(b *testing.B) {
+	body := []byte(strings.Repeat("x", 1<<20))
+	opts := &Options{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		resp := LambdaResponse{
+			StatusCode:      200,
+			Headers:         map[string]string{"Content-Type": "application/octet-stream"},
+			IsBase64Encoded: true,
+			binaryBody:      body,
+		}
+		if _, err := marshalResponse(&resp, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
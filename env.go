@@ -0,0 +1,135 @@
+package algnhsa
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is the conventional prefix of environment variables used with OptionsFromEnv.
+const DefaultEnvPrefix = "ALGNHSA"
+
+// OptionsFromEnv returns options configured from environment variables.
+// See Options.LoadEnv for the supported variables.
+func OptionsFromEnv(prefix string) (*Options, error) {
+	opts := &Options{}
+	if err := opts.LoadEnv(prefix); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// LoadEnv overrides options with the values of environment variables named <prefix>_<NAME>.
+// Unset and empty variables are ignored. The supported variables are:
+//
+//	REQUEST_TYPE               Auto, APIGatewayV1, APIGatewayV2 or ALB
+//	BINARY_CONTENT_TYPES       comma-separated list, e.g. "image/png,application/pdf"
+//	BINARY_CONTENT_ENCODINGS   comma-separated list, e.g. "gzip,br"
+//	USE_PROXY_PATH             boolean, e.g. "true" or "1"
+//	STREAM_REQUEST_BODY        boolean
+//	STRIP_STAGE                boolean
+//	SET_PATH_VALUES            boolean
+//	MAX_REQUEST_BODY_BYTES     integer
+//	DECOMPRESS_REQUEST_BODY    boolean
+//	REQUEST_ID_HEADER          header name
+//	LAMBDA_REQUEST_ID_HEADER   header name
+//	PROPAGATE_TRACE            boolean
+//	DEBUG_LOG                  boolean
+//
+// All invalid values are reported in the returned error.
+func (opts *Options) LoadEnv(prefix string) error {
+	env := envLoader{prefix: prefix}
+	env.requestType("REQUEST_TYPE", &opts.RequestType)
+	env.list("BINARY_CONTENT_TYPES", &opts.BinaryContentTypes)
+	env.list("BINARY_CONTENT_ENCODINGS", &opts.BinaryContentEncodings)
+	env.bool("USE_PROXY_PATH", &opts.UseProxyPath)
+	env.bool("STREAM_REQUEST_BODY", &opts.StreamRequestBody)
+	env.bool("STRIP_STAGE", &opts.StripStage)
+	env.bool("SET_PATH_VALUES", &opts.SetPathValues)
+	env.int64("MAX_REQUEST_BODY_BYTES", &opts.MaxRequestBodyBytes)
+	env.bool("DECOMPRESS_REQUEST_BODY", &opts.DecompressRequestBody)
+	env.string("REQUEST_ID_HEADER", &opts.RequestIDHeader)
+	env.string("LAMBDA_REQUEST_ID_HEADER", &opts.LambdaRequestIDHeader)
+	env.bool("PROPAGATE_TRACE", &opts.PropagateTrace)
+	env.bool("DEBUG_LOG", &opts.DebugLog)
+	return errors.Join(env.errs...)
+}
+
+// envLoader reads prefixed environment variables, collecting parsing errors.
+type envLoader struct {
+	prefix string
+	errs   []error
+}
+
+func (env *envLoader) lookup(name string) (string, string, bool) {
+	if env.prefix != "" {
+		name = env.prefix + "_" + name
+	}
+	v := strings.TrimSpace(os.Getenv(name))
+	return name, v, v != ""
+}
+
+func (env *envLoader) fail(name string, v string, err error) {
+	env.errs = append(env.errs, fmt.Errorf("invalid %s value %q: %w", name, v, err))
+}
+
+func (env *envLoader) string(name string, dst *string) {
+	if _, v, ok := env.lookup(name); ok {
+		*dst = v
+	}
+}
+
+func (env *envLoader) list(name string, dst *[]string) {
+	_, v, ok := env.lookup(name)
+	if !ok {
+		return
+	}
+	var values []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	*dst = values
+}
+
+func (env *envLoader) bool(name string, dst *bool) {
+	name, v, ok := env.lookup(name)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		env.fail(name, v, errors.New("expected a boolean"))
+		return
+	}
+	*dst = b
+}
+
+func (env *envLoader) int64(name string, dst *int64) {
+	name, v, ok := env.lookup(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		env.fail(name, v, errors.New("expected a non-negative integer"))
+		return
+	}
+	*dst = n
+}
+
+func (env *envLoader) requestType(name string, dst *RequestType) {
+	name, v, ok := env.lookup(name)
+	if !ok {
+		return
+	}
+	t, err := ParseRequestType(v)
+	if err != nil {
+		env.fail(name, v, err)
+		return
+	}
+	*dst = t
+}
@@ -0,0 +1,60 @@
+package algnhsa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequestType(t *testing.T) {
+	asrt := assert.New(t)
+	for _, rt := range []RequestType{RequestTypeAuto, RequestTypeAPIGatewayV1, RequestTypeAPIGatewayV2, RequestTypeALB} {
+		parsed, err := ParseRequestType(rt.String())
+		asrt.NoError(err)
+		asrt.Equal(rt, parsed)
+	}
+	parsed, err := ParseRequestType("alb")
+	asrt.NoError(err)
+	asrt.Equal(RequestTypeALB, parsed)
+	_, err = ParseRequestType("lambda")
+	asrt.EqualError(err, `unknown request type "lambda"; supported types: Auto, APIGatewayV1, APIGatewayV2, ALB`)
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	asrt := assert.New(t)
+	t.Setenv("ALGNHSA_REQUEST_TYPE", "APIGatewayV2")
+	t.Setenv("ALGNHSA_BINARY_CONTENT_TYPES", "image/png, application/pdf,")
+	t.Setenv("ALGNHSA_USE_PROXY_PATH", "1")
+	t.Setenv("ALGNHSA_MAX_REQUEST_BODY_BYTES", "1048576")
+	t.Setenv("ALGNHSA_REQUEST_ID_HEADER", "X-Request-Id")
+	t.Setenv("ALGNHSA_DEBUG_LOG", "false")
+	t.Setenv("ALGNHSA_STRIP_STAGE", "")
+
+	opts, err := OptionsFromEnv(DefaultEnvPrefix)
+	asrt.NoError(err)
+	asrt.Equal(&Options{
+		RequestType:         RequestTypeAPIGatewayV2,
+		BinaryContentTypes:  []string{"image/png", "application/pdf"},
+		UseProxyPath:        true,
+		MaxRequestBodyBytes: 1 << 20,
+		RequestIDHeader:     "X-Request-Id",
+	}, opts)
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	t.Setenv("APP_DEBUG_LOG", "true")
+	opts := &Options{UseProxyPath: true}
+	assert.NoError(t, opts.LoadEnv("APP"))
+	assert.True(t, opts.UseProxyPath)
+	assert.True(t, opts.DebugLog)
+}
+
+func TestOptionsFromEnvInvalid(t *testing.T) {
+	t.Setenv("ALGNHSA_REQUEST_TYPE", "lambda")
+	t.Setenv("ALGNHSA_DEBUG_LOG", "yes")
+	t.Setenv("ALGNHSA_MAX_REQUEST_BODY_BYTES", "1MB")
+	_, err := OptionsFromEnv(DefaultEnvPrefix)
+	assert.EqualError(t, err, `invalid ALGNHSA_REQUEST_TYPE value "lambda": unknown request type "lambda"; supported types: Auto, APIGatewayV1, APIGatewayV2, ALB
+invalid ALGNHSA_MAX_REQUEST_BODY_BYTES value "1MB": expected a non-negative integer
+invalid ALGNHSA_DEBUG_LOG value "yes": expected a boolean`)
+}
@@ -0,0 +1,74 @@
+package algnhsa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// InvocationError is returned by the Lambda handler when the invocation fails with a function error.
+// It's reported to the Lambda runtime instead of the HTTP response.
+type InvocationError struct {
+	// StatusCode is the status code of the response written by the HTTP handler.
+	StatusCode int
+
+	// Body is the decoded body of the response written by the HTTP handler.
+	Body string
+
+	// Err is the error passed to FailInvocation or returned by Options.ErrorMapper.
+	Err error
+}
+
+// maxErrorBodyLen is the maximum length of the response body included in the error message.
+const maxErrorBodyLen = 512
+
+// Error returns the error message including the status code and the response body truncated to 512 bytes.
+// The Lambda runtime reports only the message, e.g. to asynchronous invocation destinations.
+func (e *InvocationError) Error() string {
+	body := e.Body
+	if len(body) > maxErrorBodyLen {
+		// Cut at a rune boundary, so a multi-byte character isn't split.
+		n := maxErrorBodyLen
+		for n > 0 && !utf8.RuneStart(body[n]) {
+			n--
+		}
+		body = body[:n] + "..."
+	}
+	return fmt.Sprintf("invocation failed with status %d: %v: response body %q", e.StatusCode, e.Err, body)
+}
+
+func (e *InvocationError) Unwrap() error {
+	return e.Err
+}
+
+// FailInvocation makes the invocation serving the request fail with a function error after the handler returns.
+// The HTTP response is returned to the Lambda runtime as an *InvocationError instead, event sources and
+// asynchronous callers can retry the invocation. Metrics and access logs still record the response.
+// ctx must be the request context or derived from it. FailInvocation has no effect outside of a Lambda invocation.
+// Passing a nil error cancels a previous call.
+func FailInvocation(ctx context.Context, err error) {
+	if state, ok := ctx.Value(invocationContextKey).(*invocationState); ok {
+		state.failure = err
+	}
+}
+
+// invocationFailure returns the function error the invocation fails with, or nil.
+func invocationFailure(ctx context.Context, r *http.Request, resp *LambdaResponse, opts *Options) error {
+	var err error
+	if state, ok := ctx.Value(invocationContextKey).(*invocationState); ok {
+		err = state.failure
+	}
+	if opts.ErrorMapper != nil {
+		resp.encodeBody()
+		err = opts.ErrorMapper(r, resp, err)
+	}
+	if err == nil {
+		return nil
+	}
+	return &InvocationError{
+		StatusCode: resp.StatusCode,
+		Body:       resp.decodedBody(),
+		Err:        err,
+	}
+}
@@ -0,0 +1,89 @@
+package algnhsa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestRetry = errors.New("retry")
+
+func TestFailInvocation(t *testing.T) {
+	asrt := assert.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FailInvocation(r.Context(), errTestRetry)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	lh := lambdaHandler{httpHandler: handler, opts: &Options{}}
+	data, err := lh.Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.Nil(data)
+	asrt.ErrorIs(err, errTestRetry)
+	var invErr *InvocationError
+	asrt.True(errors.As(err, &invErr))
+	asrt.Equal(http.StatusServiceUnavailable, invErr.StatusCode)
+	asrt.Equal("unavailable\n", invErr.Body)
+	asrt.EqualError(err, `invocation failed with status 503: retry: response body "unavailable\n"`)
+
+	// No effect outside of an invocation.
+	FailInvocation(context.Background(), errTestRetry)
+}
+
+func TestErrorMapper(t *testing.T) {
+	asrt := assert.New(t)
+	status := http.StatusOK
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	opts := &Options{
+		ErrorMapper: func(r *http.Request, resp *LambdaResponse, err error) error {
+			if resp.StatusCode >= 500 {
+				return fmt.Errorf("%s %s: %w", r.Method, r.URL.Path, errTestRetry)
+			}
+			return err
+		},
+	}
+	lh := lambdaHandler{httpHandler: handler, opts: opts}
+
+	_, err := lh.Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+
+	status = http.StatusBadGateway
+	_, err = lh.Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.ErrorIs(err, errTestRetry)
+	asrt.EqualError(err, `invocation failed with status 502: POST /my/path: retry: response body ""`)
+}
+
+func TestInvocationErrorTruncatesBody(t *testing.T) {
+	err := &InvocationError{StatusCode: 500, Body: strings.Repeat("x", 600), Err: errTestRetry}
+	assert.Equal(t, `invocation failed with status 500: retry: response body "`+strings.Repeat("x", 512)+`..."`, err.Error())
+}
+
+func TestInvocationErrorTruncatesMultiByteBody(t *testing.T) {
+	// "é" is 2 bytes, byte 512 is in the middle of the 257th character.
+	err := &InvocationError{StatusCode: 500, Body: "x" + strings.Repeat("é", 300), Err: errTestRetry}
+	assert.Equal(t, `invocation failed with status 500: retry: response body "x`+strings.Repeat("é", 255)+`..."`, err.Error())
+}
+
+func TestFailInvocationRecorded(t *testing.T) {
+	asrt := assert.New(t)
+	var metricsBuf, accessLogBuf bytes.Buffer
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FailInvocation(r.Context(), errTestRetry)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	opts := &Options{
+		Metrics:   &EMFMetrics{Writer: &metricsBuf},
+		AccessLog: &AccessLog{Writer: &accessLogBuf, Fields: []string{"status"}},
+	}
+	lh := lambdaHandler{httpHandler: handler, opts: opts}
+	_, err := lh.Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.ErrorIs(err, errTestRetry)
+	asrt.Contains(metricsBuf.String(), `"StatusClass":"5xx"`)
+	asrt.Equal(`{"status":503}`+"\n", accessLogBuf.String())
+}
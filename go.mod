@@ -1,6 +1,6 @@
 module github.com/akrylysov/algnhsa
 
-go 1.18
+go 1.22
 
 require (
 	github.com/aws/aws-lambda-go v1.48.0
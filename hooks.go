@@ -0,0 +1,54 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+)
+
+// Hooks are callbacks called at defined points of every invocation.
+// A hook returning an error fails the invocation with that error.
+type Hooks struct {
+	// AfterDecode is called after the Lambda event is decoded.
+	// The event is events.APIGatewayProxyRequest, events.APIGatewayV2HTTPRequest or events.ALBTargetGroupRequest.
+	AfterDecode func(ctx context.Context, event interface{}) error
+
+	// AfterRequest is called after the HTTP request is built, before the handler is called.
+	// It isn't called for requests rejected by Options.OriginVerifier.
+	AfterRequest func(r *http.Request) error
+
+	// AfterHandler is called after the handler returns and its output is converted to the Lambda response.
+	AfterHandler func(r *http.Request, resp *LambdaResponse) error
+
+	// BeforeMarshal is called before the Lambda response is encoded.
+	BeforeMarshal func(ctx context.Context, resp *LambdaResponse) error
+}
+
+func (h *Hooks) afterDecode(ctx context.Context, event interface{}) error {
+	if h.AfterDecode == nil {
+		return nil
+	}
+	return h.AfterDecode(ctx, event)
+}
+
+func (h *Hooks) afterRequest(r *http.Request) error {
+	if h.AfterRequest == nil {
+		return nil
+	}
+	return h.AfterRequest(r)
+}
+
+func (h *Hooks) afterHandler(r *http.Request, resp *LambdaResponse) error {
+	if h.AfterHandler == nil {
+		return nil
+	}
+	resp.encodeBody()
+	return h.AfterHandler(r, resp)
+}
+
+func (h *Hooks) beforeMarshal(ctx context.Context, resp *LambdaResponse) error {
+	if h.BeforeMarshal == nil {
+		return nil
+	}
+	resp.encodeBody()
+	return h.BeforeMarshal(ctx, resp)
+}
@@ -0,0 +1,119 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	asrt := assert.New(t)
+
+	var calls []string
+	hooks := Hooks{
+		AfterDecode: func(ctx context.Context, event interface{}) error {
+			_, ok := event.(events.APIGatewayV2HTTPRequest)
+			asrt.True(ok)
+			calls = append(calls, "AfterDecode")
+			return nil
+		},
+		AfterRequest: func(r *http.Request) error {
+			r.Header.Set("X-Hook", "1")
+			calls = append(calls, "AfterRequest")
+			return nil
+		},
+		AfterHandler: func(r *http.Request, resp *LambdaResponse) error {
+			asrt.Equal("1", r.Header.Get("X-Hook"))
+			resp.StatusCode = http.StatusAccepted
+			calls = append(calls, "AfterHandler")
+			return nil
+		},
+		BeforeMarshal: func(ctx context.Context, resp *LambdaResponse) error {
+			resp.Headers["X-Hook"] = "2"
+			calls = append(calls, "BeforeMarshal")
+			return nil
+		},
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "handler")
+			io.WriteString(w, r.Header.Get("X-Hook"))
+		}),
+		opts: &Options{Hooks: hooks},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+
+	var r events.APIGatewayV2HTTPResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal(http.StatusAccepted, r.StatusCode)
+	asrt.Equal("1", r.Body)
+	asrt.Equal("2", r.Headers["X-Hook"])
+	asrt.Equal([]string{"AfterDecode", "AfterRequest", "handler", "AfterHandler", "BeforeMarshal"}, calls)
+}
+
+func TestHooksError(t *testing.T) {
+	hookErr := errors.New("hook error")
+	handlerCalled := false
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+		}),
+		opts: &Options{Hooks: Hooks{
+			AfterRequest: func(r *http.Request) error {
+				return hookErr
+			},
+		}},
+	}
+	_, err := lh.Invoke(context.Background(), []byte(apiGatewayV1TestEvent))
+	assert.ErrorIs(t, err, hookErr)
+	assert.False(t, handlerCalled)
+}
+
+func TestHooksOriginVerifier(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+
+	var afterRequestCalls int
+	opts := &Options{
+		OriginVerifier: &OriginVerifier{Secrets: []string{"secret"}},
+		Hooks: Hooks{
+			AfterRequest: func(r *http.Request) error {
+				afterRequestCalls++
+				asrt.Empty(r.Header.Values("X-Origin-Verify"))
+				return nil
+			},
+		},
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		opts:        opts,
+	}
+
+	for _, tc := range []struct {
+		secret         string
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{"secret", http.StatusOK, 1},
+		{"wrong", http.StatusForbidden, 1},
+	} {
+		event.Headers["x-origin-verify"] = tc.secret
+		payload, err := json.Marshal(event)
+		asrt.NoError(err)
+		responseBytes, err := lh.Invoke(context.Background(), payload)
+		asrt.NoError(err)
+		var r events.APIGatewayV2HTTPResponse
+		asrt.NoError(json.Unmarshal(responseBytes, &r))
+		asrt.Equal(tc.expectedStatus, r.StatusCode)
+		asrt.Equal(tc.expectedCalls, afterRequestCalls)
+	}
+}
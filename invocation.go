@@ -0,0 +1,111 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+type contextKey int
+
+const invocationContextKey contextKey = iota
+
+// invoked is set after the first invocation in the execution environment.
+var invoked atomic.Bool
+
+// invocationState is the per-invocation state stored in the request context.
+type invocationState struct {
+	coldStart   bool
+	traceHeader string
+	failure     error
+}
+
+func newInvocationContext(ctx context.Context) context.Context {
+	state := &invocationState{
+		coldStart: !invoked.Swap(true),
+	}
+	return context.WithValue(ctx, invocationContextKey, state)
+}
+
+// Invocation describes the Lambda function invocation that is serving the request.
+type Invocation struct {
+	// Lambda is the Lambda context. It's nil when the handler isn't running in the Lambda runtime.
+	Lambda *lambdacontext.LambdaContext
+
+	// ColdStart reports whether it's the first invocation in the execution environment.
+	ColdStart bool
+
+	// Deadline is the time the invocation times out. It's zero when there is no deadline.
+	Deadline time.Time
+
+	// RequestID is the API Gateway request ID. It's empty for ALB requests.
+	RequestID string
+}
+
+// LambdaRequestID returns the AWS request ID of the invocation.
+func (inv Invocation) LambdaRequestID() string {
+	if inv.Lambda == nil {
+		return ""
+	}
+	return inv.Lambda.AwsRequestID
+}
+
+// FunctionARN returns the ARN of the invoked function.
+func (inv Invocation) FunctionARN() string {
+	if inv.Lambda == nil {
+		return ""
+	}
+	return inv.Lambda.InvokedFunctionArn
+}
+
+// RemainingTime returns the time left before the invocation times out.
+// It returns zero when there is no deadline.
+func (inv Invocation) RemainingTime() time.Duration {
+	if inv.Deadline.IsZero() {
+		return 0
+	}
+	return time.Until(inv.Deadline)
+}
+
+// InvocationFromContext returns the Lambda function invocation that is serving the request.
+func InvocationFromContext(ctx context.Context) (Invocation, bool) {
+	state, ok := ctx.Value(invocationContextKey).(*invocationState)
+	if !ok {
+		return Invocation{}, false
+	}
+	inv := Invocation{
+		ColdStart: state.coldStart,
+	}
+	inv.Lambda, _ = lambdacontext.FromContext(ctx)
+	inv.Deadline, _ = ctx.Deadline()
+	if rc, ok := RequestContextFromContext(ctx); ok {
+		inv.RequestID = rc.RequestID
+	}
+	return inv, true
+}
+
+// setRequestIDHeaders sets the request ID response headers enabled in opts.
+func setRequestIDHeaders(ctx context.Context, header http.Header, opts *Options) {
+	if opts.RequestIDHeader == "" && opts.LambdaRequestIDHeader == "" {
+		return
+	}
+	inv, ok := InvocationFromContext(ctx)
+	if !ok {
+		return
+	}
+	if opts.LambdaRequestIDHeader != "" && inv.LambdaRequestID() != "" {
+		header.Set(opts.LambdaRequestIDHeader, inv.LambdaRequestID())
+	}
+	if opts.RequestIDHeader != "" {
+		requestID := inv.RequestID
+		if requestID == "" {
+			requestID = inv.LambdaRequestID()
+		}
+		if requestID != "" {
+			header.Set(opts.RequestIDHeader, requestID)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvocationFromContext(t *testing.T) {
+	asrt := assert.New(t)
+
+	lc := &lambdacontext.LambdaContext{
+		AwsRequestID:       "lambda-request-id",
+		InvokedFunctionArn: "arn:aws:lambda:us-east-1:123456789012:function:test",
+	}
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	var invocations []Invocation
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		inv, ok := InvocationFromContext(r.Context())
+		asrt.True(ok)
+		invocations = append(invocations, inv)
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{},
+	}
+	for i := 0; i < 2; i++ {
+		_, err := lh.Invoke(ctx, []byte(apiGatewayV2TestEvent))
+		asrt.NoError(err)
+	}
+
+	inv := invocations[1]
+	asrt.False(inv.ColdStart)
+	asrt.Equal("id", inv.RequestID)
+	asrt.Equal("lambda-request-id", inv.LambdaRequestID())
+	asrt.Equal("arn:aws:lambda:us-east-1:123456789012:function:test", inv.FunctionARN())
+	asrt.Greater(inv.RemainingTime(), time.Duration(0))
+
+	_, ok := InvocationFromContext(context.Background())
+	asrt.False(ok)
+}
+
+func TestRequestIDHeaders(t *testing.T) {
+	asrt := assert.New(t)
+
+	lc := &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id"}
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		opts: &Options{
+			RequestIDHeader:       "X-Request-Id",
+			LambdaRequestIDHeader: "X-Amzn-Lambda-Request-Id",
+		},
+	}
+
+	responseBytes, err := lh.Invoke(ctx, []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+	var r events.APIGatewayV2HTTPResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal("id", r.Headers["X-Request-Id"])
+	asrt.Equal("lambda-request-id", r.Headers["X-Amzn-Lambda-Request-Id"])
+
+	responseBytes, err = lh.Invoke(ctx, []byte(albTestEvent))
+	asrt.NoError(err)
+	var albResp events.ALBTargetGroupResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &albResp))
+	asrt.Equal([]string{"lambda-request-id"}, albResp.MultiValueHeaders["X-Request-Id"])
+}
@@ -0,0 +1,87 @@
+package algnhsa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const defaultLocalAddr = ":8080"
+
+// runningOnLambda reports whether the process is started by the Lambda runtime.
+func runningOnLambda() bool {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" || os.Getenv("_LAMBDA_SERVER_PORT") != ""
+}
+
+// localAddr returns the address of the local HTTP server.
+func localAddr(opts *Options) string {
+	if opts.LocalAddr != "" {
+		return opts.LocalAddr
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return defaultLocalAddr
+}
+
+// localHandler wraps the handler with the request protections configured in the options:
+// it removes the AuthorizerHeaders, verifies the origin, decompresses and limits the request body.
+func localHandler(handler http.Handler, opts *Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		// There is no authorizer outside of Lambda, the headers are only removed.
+		for _, name := range opts.AuthorizerHeaders {
+			r.Header.Del(name)
+		}
+		if opts.OriginVerifier != nil && !opts.OriginVerifier.verify(opts.OriginVerifier.removeHeader(nil, r.Header)) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if decompressRequestBody(w, r, opts) && limitRequestBody(w, r, opts) {
+			handler.ServeHTTP(w, r)
+		}
+	})
+}
+
+// listenAndServeLocal serves the handler with a regular HTTP server.
+// AuthorizerHeaders, OriginVerifier, DecompressRequestBody and the request body limits are applied to the requests.
+// Other options depend on Lambda events and have no effect.
+// The server is shut down gracefully on SIGTERM or interrupt, calling the functions registered with RegisterOnShutdown.
+func listenAndServeLocal(handler http.Handler, opts *Options) {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	if opts.AllowedSources != nil {
+		fmt.Println("WARNING: AllowedSources has no effect outside of AWS Lambda, requests from all sources are accepted")
+	}
+	ctx, shutdown := opts.shutdownContext()
+	srv := &http.Server{
+		Addr:        localAddr(opts),
+		Handler:     localHandler(handler, opts),
+		BaseContext: func(_ net.Listener) context.Context { return ctx },
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		<-signalCtx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			fmt.Printf("HTTP server shutdown failed: %v\n", err)
+		}
+		shutdown()
+	}()
+
+	fmt.Printf("Not running on AWS Lambda, listening on %s\n", srv.Addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Printf("HTTP server failed: %v\n", err)
+		os.Exit(1)
+	}
+	<-stopped
+}
@@ -0,0 +1,74 @@
+package algnhsa
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunningOnLambda(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "")
+	t.Setenv("_LAMBDA_SERVER_PORT", "")
+	assert.False(t, runningOnLambda())
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "127.0.0.1:9001")
+	assert.True(t, runningOnLambda())
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "")
+	t.Setenv("_LAMBDA_SERVER_PORT", "8001")
+	assert.True(t, runningOnLambda())
+}
+
+func TestLocalAddr(t *testing.T) {
+	t.Setenv("PORT", "")
+	assert.Equal(t, ":8080", localAddr(&Options{}))
+	t.Setenv("PORT", "3000")
+	assert.Equal(t, ":3000", localAddr(&Options{}))
+	assert.Equal(t, "127.0.0.1:9000", localAddr(&Options{LocalAddr: "127.0.0.1:9000"}))
+}
+
+func TestLocalHandler(t *testing.T) {
+	asrt := assert.New(t)
+	opts := &Options{
+		AuthorizerHeaders:   map[string]string{"sub": "X-User-Id"},
+		OriginVerifier:      &OriginVerifier{Secrets: []string{"secret"}},
+		MaxRequestBodyBytes: 5,
+	}
+	var got *http.Request
+	var gotBody string
+	handler := localHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}), opts)
+
+	for _, tc := range []struct {
+		origin         string
+		body           string
+		expectedStatus int
+	}{
+		{"secret", "hello", http.StatusOK},
+		{"wrong", "hello", http.StatusForbidden},
+		{"", "hello", http.StatusForbidden},
+		{"secret", "hello!", http.StatusRequestEntityTooLarge},
+	} {
+		got = nil
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+		r.Header.Set("X-User-Id", "spoofed")
+		if tc.origin != "" {
+			r.Header.Set("X-Origin-Verify", tc.origin)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		asrt.Equal(tc.expectedStatus, w.Code, tc)
+		if tc.expectedStatus != http.StatusOK {
+			asrt.Nil(got)
+			continue
+		}
+		asrt.Empty(got.Header.Values("X-User-Id"))
+		asrt.Empty(got.Header.Values("X-Origin-Verify"))
+		asrt.Equal(tc.body, gotBody)
+	}
+}
@@ -0,0 +1,165 @@
+package algnhsa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+AWS Documentation:
+
+- https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+*/
+
+const defaultMetricsNamespace = "algnhsa"
+
+// Metric dimension names.
+const (
+	DimensionRequestType = "RequestType"
+	DimensionRoute       = "Route"
+	DimensionMethod      = "Method"
+	DimensionStatusClass = "StatusClass"
+)
+
+var defaultMetricsDimensions = [][]string{
+	{DimensionRequestType, DimensionRoute, DimensionMethod, DimensionStatusClass},
+}
+
+var emfMetricDefinitions = []emfMetricDefinition{
+	{Name: "Latency", Unit: "Milliseconds"},
+	{Name: "ResponseSize", Unit: "Bytes"},
+	{Name: "Base64Encoded", Unit: "Count"},
+}
+
+// EMFMetrics writes a CloudWatch Embedded Metric Format record for every request.
+// CloudWatch extracts the metrics from the function logs, no CloudWatch API calls are made.
+// The record has the Latency, ResponseSize and Base64Encoded metrics.
+// Records are written synchronously with a single Write call before the invocation returns,
+// because the execution environment can be frozen as soon as it returns.
+type EMFMetrics struct {
+	// Namespace is the CloudWatch metrics namespace.
+	// By default, "algnhsa" is used.
+	Namespace string
+
+	// Dimensions are the dimension sets the metrics are aggregated by.
+	// Valid dimension names are DimensionRequestType, DimensionRoute, DimensionMethod and DimensionStatusClass.
+	// By default, the metrics are aggregated by all four dimensions.
+	// New panics if a dimension name is unknown, CloudWatch would reject such records.
+	Dimensions [][]string
+
+	// Writer is where the records are written to.
+	// By default, os.Stdout is used.
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+type emfMetricDefinition struct {
+	Name string
+	Unit string
+}
+
+type emfDirective struct {
+	Namespace  string
+	Dimensions [][]string
+	Metrics    []emfMetricDefinition
+}
+
+type emfMetadata struct {
+	Timestamp         int64
+	CloudWatchMetrics []emfDirective
+}
+
+type emfRecord struct {
+	AWS           emfMetadata `json:"_aws"`
+	RequestType   string
+	Route         string
+	Method        string
+	StatusClass   string
+	Latency       float64
+	ResponseSize  int
+	Base64Encoded int
+}
+
+var emfBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// validate checks the dimension names.
+func (m *EMFMetrics) validate() error {
+	for _, set := range m.Dimensions {
+		for _, name := range set {
+			switch name {
+			case DimensionRequestType, DimensionRoute, DimensionMethod, DimensionStatusClass:
+			default:
+				return fmt.Errorf("unknown metrics dimension %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+func statusClass(statusCode int) string {
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+// emit writes the metrics record for a request.
+func (m *EMFMetrics) emit(event lambdaRequest, resp LambdaResponse, start time.Time) error {
+	now := time.Now()
+	namespace := m.Namespace
+	if namespace == "" {
+		namespace = defaultMetricsNamespace
+	}
+	dimensions := m.Dimensions
+	if len(dimensions) == 0 {
+		dimensions = defaultMetricsDimensions
+	}
+	route := "-"
+	if _, r, _, ok := routeMatchFromContext(event.Context); ok {
+		route = r
+	}
+	record := emfRecord{
+		AWS: emfMetadata{
+			Timestamp: now.UnixMilli(),
+			CloudWatchMetrics: []emfDirective{{
+				Namespace:  namespace,
+				Dimensions: dimensions,
+				Metrics:    emfMetricDefinitions,
+			}},
+		},
+		RequestType:  event.requestType.String(),
+		Route:        route,
+		Method:       event.HTTPMethod,
+		StatusClass:  statusClass(resp.StatusCode),
+		Latency:      float64(now.Sub(start).Microseconds()) / 1000,
+		ResponseSize: resp.bodySize(),
+	}
+	if resp.IsBase64Encoded {
+		record.Base64Encoded = 1
+	}
+
+	buf := emfBufferPool.Get().(*bytes.Buffer)
+	defer emfBufferPool.Put(buf)
+	buf.Reset()
+	if err := json.NewEncoder(buf).Encode(record); err != nil {
+		return err
+	}
+
+	w := m.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	// Write the whole record at once, so records from concurrent invocations don't interleave.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := w.Write(buf.Bytes())
+	return err
+}
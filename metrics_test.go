@@ -0,0 +1,64 @@
+package algnhsa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEMFMetrics(t *testing.T) {
+	asrt := assert.New(t)
+
+	var buf bytes.Buffer
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "Hello from Lambda!")
+		}),
+		opts: &Options{
+			Metrics:            &EMFMetrics{Namespace: "test", Writer: &buf},
+			BinaryContentTypes: []string{"*/*"},
+		},
+	}
+	lh.opts.init()
+	_, err := lh.Invoke(context.Background(), []byte(apiGatewayV1TestEvent))
+	asrt.NoError(err)
+
+	var record map[string]interface{}
+	asrt.NoError(json.Unmarshal(buf.Bytes(), &record))
+	asrt.Equal("APIGatewayV1", record["RequestType"])
+	asrt.Equal("/my/path", record["Route"])
+	asrt.Equal("GET", record["Method"])
+	asrt.Equal("4xx", record["StatusClass"])
+	asrt.Equal(float64(18), record["ResponseSize"])
+	asrt.Equal(float64(1), record["Base64Encoded"])
+	asrt.Contains(record, "Latency")
+
+	aws := record["_aws"].(map[string]interface{})
+	directive := aws["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+	asrt.Equal("test", directive["Namespace"])
+	asrt.Equal([]interface{}{[]interface{}{"RequestType", "Route", "Method", "StatusClass"}}, directive["Dimensions"])
+	asrt.Len(directive["Metrics"], 3)
+
+	buf.Reset()
+	_, err = lh.Invoke(context.Background(), []byte(albTestEvent))
+	asrt.NoError(err)
+	asrt.NoError(json.Unmarshal(buf.Bytes(), &record))
+	asrt.Equal("ALB", record["RequestType"])
+	asrt.Equal("-", record["Route"])
+}
+
+func TestEMFMetricsUnknownDimension(t *testing.T) {
+	opts := &Options{Metrics: &EMFMetrics{Dimensions: [][]string{{DimensionRoute, "Path"}}}}
+	assert.PanicsWithValue(t, `algnhsa: unknown metrics dimension "Path"`, func() {
+		New(nil, opts)
+	})
+	assert.NotPanics(t, func() {
+		New(nil, &Options{Metrics: &EMFMetrics{Dimensions: [][]string{{DimensionRoute}, {}}}})
+	})
+}
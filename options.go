@@ -1,5 +1,16 @@
 package algnhsa
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
 type RequestType int
 
 const (
@@ -9,6 +20,30 @@ const (
 	RequestTypeALB
 )
 
+func (t RequestType) String() string {
+	switch t {
+	case RequestTypeAuto:
+		return "Auto"
+	case RequestTypeAPIGatewayV1:
+		return "APIGatewayV1"
+	case RequestTypeAPIGatewayV2:
+		return "APIGatewayV2"
+	case RequestTypeALB:
+		return "ALB"
+	}
+	return "RequestType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// ParseRequestType parses a request type name returned by RequestType.String, ignoring case.
+func ParseRequestType(s string) (RequestType, error) {
+	for _, t := range []RequestType{RequestTypeAuto, RequestTypeAPIGatewayV1, RequestTypeAPIGatewayV2, RequestTypeALB} {
+		if strings.EqualFold(s, t.String()) {
+			return t, nil
+		}
+	}
+	return RequestTypeAuto, fmt.Errorf("unknown request type %q; supported types: Auto, APIGatewayV1, APIGatewayV2, ALB", s)
+}
+
 type set[T comparable] struct {
 	items map[T]struct{}
 }
@@ -49,11 +84,158 @@ type Options struct {
 	// Strips the base path mapping when using a custom domain with API Gateway.
 	UseProxyPath bool
 
+	// StreamRequestBody makes the request body read directly from the Lambda payload,
+	// instead of decoding it into the event first. It reduces memory usage for large request bodies.
+	// The Body field of the event in the request context is left empty.
+	StreamRequestBody bool
+
+	// MaxRequestBodyBytes limits the size of the decoded request body.
+	// Requests with larger bodies are rejected with 413 Request Entity Too Large before reaching the handler.
+	// The request body is also wrapped with http.MaxBytesReader.
+	// Zero means no limit.
+	MaxRequestBodyBytes int64
+
+	// RequestBodyLimits sets request body size limits for path prefixes, e.g. {"/upload/": 10 << 20}.
+	// The limit of the longest matching prefix is used, MaxRequestBodyBytes is used if no prefix matches.
+	RequestBodyLimits map[string]int64
+
+	// DecompressRequestBody decompresses gzip and deflate request bodies as the handler reads them.
+	// The Content-Encoding and Content-Length headers are removed and ContentLength is set to -1.
+	// The decompressed size is limited by the request body limit, or 8 MiB if no limit is set.
+	// Reading past the limit returns *http.MaxBytesError. On Lambda, the handler response is then replaced
+	// with 413 Request Entity Too Large.
+	// Bodies with a malformed compression header are rejected with 400 Bad Request.
+	DecompressRequestBody bool
+
+	// RequestBodyDecoders adds decoders for other content encodings, e.g. zstd, used with DecompressRequestBody.
+	// The keys are lower case Content-Encoding values.
+	RequestBodyDecoders map[string]func(io.Reader) (io.Reader, error)
+
+	// StripStage removes the stage name prefix from the request path of requests to the default execute-api endpoint.
+	// API Gateway V2 includes a named stage in rawPath when the default execute-api endpoint is used,
+	// while API Gateway V1 never does. With StripStage the handler sees the same path
+	// regardless of whether the request came through a custom domain or the default endpoint.
+	// Use StageFromContext to get the stage name.
+	StripStage bool
+
+	// SetPathValues makes API Gateway path parameters available through http.Request.PathValue.
+	// Use RouteFromContext to get the matched API Gateway resource or route key.
+	SetPathValues bool
+
+	// AuthorizerHeaders maps Lambda authorizer context keys or JWT claims to request header names,
+	// e.g. {"sub": "X-User-Id"}.
+	// Headers with these names sent by the client are always removed.
+	// When several keys map to the same header, the first key in lexical order that has a value wins.
+	AuthorizerHeaders map[string]string
+
+	// OriginVerifier rejects requests without a valid shared secret header with 403 Forbidden
+	// before they reach the handler.
+	OriginVerifier *OriginVerifier
+
+	// AllowedSources restricts the accepted event sources.
+	// Events from other sources fail the invocation with ErrSourceNotAllowed.
+	AllowedSources *SourceAllowList
+
+	// RequestIDHeader sets the response header the API Gateway request ID is returned in, e.g. "X-Request-Id".
+	// The Lambda request ID is used for ALB requests.
+	RequestIDHeader string
+
+	// LambdaRequestIDHeader sets the response header the Lambda request ID is returned in,
+	// e.g. "X-Amzn-Lambda-Request-Id".
+	LambdaRequestIDHeader string
+
+	// PropagateTrace adds the active AWS X-Ray trace header to the request as X-Amzn-Trace-Id
+	// and as a W3C traceparent header, unless the request already has them.
+	// Use TraceHeaderFromContext to get the active trace header.
+	PropagateTrace bool
+
+	// Tracer traces invocations, separating the adapter overhead from the handler time.
+	Tracer Tracer
+
+	// Metrics emits CloudWatch Embedded Metric Format metrics for every request.
+	Metrics *EMFMetrics
+
+	// AccessLog writes an access log record for every request.
+	AccessLog *AccessLog
+
+	// RequestContext optionally derives the request context from the Lambda event, e.g. to add a tenant or a logger.
+	// It's called after the event is decoded, before the HTTP request is built.
+	// The event is events.APIGatewayProxyRequest, events.APIGatewayV2HTTPRequest or events.ALBTargetGroupRequest.
+	// It must return a non-nil context derived from ctx.
+	RequestContext func(ctx context.Context, event interface{}) context.Context
+
+	// ErrorMapper optionally decides whether the invocation fails with a function error after the handler returns.
+	// err is the error passed to FailInvocation, or nil. A non-nil returned error fails the invocation
+	// with an *InvocationError wrapping it, e.g. to make event sources retry 5xx responses.
+	ErrorMapper func(r *http.Request, resp *LambdaResponse, err error) error
+
+	// Hooks are callbacks called at defined points of every invocation.
+	Hooks Hooks
+
+	// DisableHTMLEscape disables escaping of <, > and & in JSON strings of the Lambda response,
+	// like lambda.WithSetEscapeHTML(false). It has no effect if JSONCodec is set.
+	DisableHTMLEscape bool
+
+	// JSONCodec replaces encoding/json for decoding Lambda events and encoding Lambda responses.
+	// By default, events are decoded with encoding/json and responses are encoded with a built-in encoder.
+	JSONCodec JSONCodec
+
+	// LambdaOptions are passed to lambda.StartWithOptions by ListenAndServe.
+	// Only the options configuring the runtime take effect: lambda.WithContext, lambda.WithContextValue
+	// and lambda.WithEnableSIGTERM. The JSON options, e.g. lambda.WithSetEscapeHTML, lambda.WithSetIndent
+	// and lambda.WithUseNumber, have no effect because algnhsa encodes and decodes the payloads itself.
+	// Use DisableHTMLEscape and JSONCodec instead.
+	LambdaOptions []lambda.Option
+
+	// onShutdown are the functions registered with RegisterOnShutdown.
+	onShutdown []func()
+
+	// LocalAddr is the address of the HTTP server started by ListenAndServe when not running on Lambda.
+	// Defaults to ":$PORT", or ":8080" if PORT isn't set.
+	LocalAddr string
+
 	// DebugLog enables printing request and response objects to stdout.
 	DebugLog bool
 }
 
+func (opts *Options) tracer() Tracer {
+	if opts.Tracer == nil {
+		return noopTracer{}
+	}
+	return opts.Tracer
+}
+
+// requestBodyLimit returns the request body size limit for the path.
+func (opts *Options) requestBodyLimit(path string) int64 {
+	limit := opts.MaxRequestBodyBytes
+	longest := -1
+	for prefix, prefixLimit := range opts.RequestBodyLimits {
+		if len(prefix) > longest && strings.HasPrefix(path, prefix) {
+			limit = prefixLimit
+			longest = len(prefix)
+		}
+	}
+	return limit
+}
+
+func (opts *Options) jsonCodec() JSONCodec {
+	if opts.JSONCodec == nil {
+		return stdJSONCodec{}
+	}
+	return opts.JSONCodec
+}
+
 func (opts *Options) init() {
 	opts.binaryContentTypes = newSet(opts.BinaryContentTypes...)
 	opts.binaryContentEncodings = newSet(opts.BinaryContentEncodings...)
+	if opts.Metrics != nil {
+		if err := opts.Metrics.validate(); err != nil {
+			panic("algnhsa: " + err.Error())
+		}
+	}
+	if opts.AccessLog != nil {
+		if err := opts.AccessLog.validate(); err != nil {
+			panic("algnhsa: " + err.Error())
+		}
+	}
 }
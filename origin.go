@@ -0,0 +1,68 @@
+package algnhsa
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+const defaultOriginVerifyHeader = "X-Origin-Verify"
+
+// OriginVerifier rejects requests that don't carry a shared secret in a header,
+// e.g. requests that bypass CloudFront and call a Lambda Function URL or an ALB directly.
+// Configure the CDN to add the header with the secret to every origin request.
+type OriginVerifier struct {
+	// Header is the name of the header with the shared secret.
+	// By default, "X-Origin-Verify" is used.
+	Header string
+
+	// Secrets are the currently accepted secrets.
+	// Multiple secrets allow rotating the secret without downtime.
+	// If empty, all requests are rejected.
+	Secrets []string
+}
+
+func (v *OriginVerifier) header() string {
+	if v.Header == "" {
+		return defaultOriginVerifyHeader
+	}
+	return v.Header
+}
+
+// removeHeader removes the secret header from the headers, matching its name case-insensitively,
+// and returns its values. As API Gateway V1 does, the multi-value headers take precedence.
+func (v *OriginVerifier) removeHeader(headers map[string]string, multiValueHeaders map[string][]string) []string {
+	name := v.header()
+	var values, singleValues []string
+	for k, vals := range multiValueHeaders {
+		if strings.EqualFold(k, name) {
+			values = append(values, vals...)
+			delete(multiValueHeaders, k)
+		}
+	}
+	for k, val := range headers {
+		if strings.EqualFold(k, name) {
+			singleValues = append(singleValues, val)
+			delete(headers, k)
+		}
+	}
+	if len(values) == 0 {
+		return singleValues
+	}
+	return values
+}
+
+// verify reports whether the values of the secret header contain exactly one of the secrets.
+func (v *OriginVerifier) verify(values []string) bool {
+	if len(values) != 1 {
+		return false
+	}
+	got := []byte(values[0])
+	match := 0
+	for _, secret := range v.Secrets {
+		if secret == "" {
+			continue
+		}
+		match |= subtle.ConstantTimeCompare(got, []byte(secret))
+	}
+	return match == 1
+}
@@ -0,0 +1,143 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginVerifier(t *testing.T) {
+	asrt := assert.New(t)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "secret header: "+r.Header.Get("X-Origin-Verify"))
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts: &Options{OriginVerifier: &OriginVerifier{
+			Secrets: []string{"old-secret", "new-secret"},
+		}},
+	}
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+
+	for _, tc := range []struct {
+		secret         string
+		expectedStatus int
+	}{
+		{"", 403},
+		{"wrong", 403},
+		{"old-secret", 200},
+		{"new-secret", 200},
+	} {
+		secret, expectedStatus := tc.secret, tc.expectedStatus
+		if secret != "" {
+			event.Headers["x-origin-verify"] = secret
+		}
+		payload, err := json.Marshal(event)
+		asrt.NoError(err)
+		responseBytes, err := lh.Invoke(context.Background(), payload)
+		asrt.NoError(err)
+		var r events.APIGatewayV2HTTPResponse
+		asrt.NoError(json.Unmarshal(responseBytes, &r))
+		asrt.Equal(expectedStatus, r.StatusCode, secret)
+		if expectedStatus == 200 {
+			asrt.Equal("secret header: ", r.Body)
+		}
+	}
+}
+
+func TestOriginVerifierALB(t *testing.T) {
+	asrt := assert.New(t)
+
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(RequestDebugDumpHandler),
+		opts: &Options{OriginVerifier: &OriginVerifier{
+			Header:  "X-Custom-Verify",
+			Secrets: []string{"secret"},
+		}},
+	}
+
+	event := events.ALBTargetGroupRequest{}
+	asrt.NoError(json.Unmarshal([]byte(albTestEvent), &event))
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+	responseBytes, err := lh.Invoke(context.Background(), payload)
+	asrt.NoError(err)
+	var r events.ALBTargetGroupResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal(403, r.StatusCode)
+
+	event.MultiValueHeaders["x-custom-verify"] = []string{"secret"}
+	payload, err = json.Marshal(event)
+	asrt.NoError(err)
+	dump, err := dumpALB(payload, *lh.opts)
+	asrt.NoError(err)
+	asrt.Equal(expectedALBDump, dump)
+}
+
+func TestOriginVerifierRemovesHeaderFromEvent(t *testing.T) {
+	asrt := assert.New(t)
+
+	v1Event := events.APIGatewayProxyRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV1TestEvent), &v1Event))
+	v1Event.Headers["X-Origin-Verify"] = "secret"
+	v1Event.MultiValueHeaders["x-origin-verify"] = []string{"secret"}
+	v1Payload, err := json.Marshal(v1Event)
+	asrt.NoError(err)
+
+	v2Event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &v2Event))
+	v2Event.Headers["x-origin-verify"] = "secret"
+	v2Payload, err := json.Marshal(v2Event)
+	asrt.NoError(err)
+
+	assertNoSecret := func(event interface{}) {
+		data, err := json.Marshal(event)
+		asrt.NoError(err)
+		asrt.NotContains(string(data), "secret")
+	}
+
+	for _, payload := range [][]byte{v1Payload, v2Payload} {
+		var afterDecodeCalls, requestContextCalls int
+		opts := &Options{
+			OriginVerifier: &OriginVerifier{Secrets: []string{"secret"}},
+			RequestContext: func(ctx context.Context, event interface{}) context.Context {
+				requestContextCalls++
+				assertNoSecret(event)
+				return ctx
+			},
+			Hooks: Hooks{
+				AfterDecode: func(ctx context.Context, event interface{}) error {
+					afterDecodeCalls++
+					assertNoSecret(event)
+					return nil
+				},
+			},
+		}
+		var status int
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			status = http.StatusOK
+			asrt.Empty(r.Header.Values("X-Origin-Verify"))
+			if event, ok := APIGatewayV1RequestFromContext(r.Context()); ok {
+				assertNoSecret(event)
+			} else {
+				event, ok := APIGatewayV2RequestFromContext(r.Context())
+				asrt.True(ok)
+				assertNoSecret(event)
+			}
+		}
+		lh := lambdaHandler{httpHandler: http.HandlerFunc(handler), opts: opts}
+		_, err := lh.Invoke(context.Background(), payload)
+		asrt.NoError(err)
+		asrt.Equal(http.StatusOK, status)
+		asrt.Equal(1, afterDecodeCalls)
+		asrt.Equal(1, requestContextCalls)
+	}
+}
@@ -0,0 +1,83 @@
+// Package algnhsaotel implements algnhsa.Tracer using OpenTelemetry.
+//
+//	algnhsa.ListenAndServe(handler, &algnhsa.Options{
+//		Tracer: algnhsaotel.NewTracer(nil),
+//	})
+package algnhsaotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/akrylysov/algnhsa"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/akrylysov/algnhsa/otel"
+
+// Tracer implements algnhsa.Tracer using an OpenTelemetry tracer.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a new Tracer using the given tracer provider.
+// If tp is nil, the global tracer provider is used.
+func NewTracer(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// Start starts a new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind algnhsa.SpanKind) (context.Context, algnhsa.Span) {
+	spanKind := trace.SpanKindInternal
+	if kind == algnhsa.SpanKindServer {
+		spanKind = trace.SpanKindServer
+	}
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(spanKind))
+	return ctx, span{s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetName(name string) {
+	s.span.SetName(name)
+}
+
+func (s span) SetAttributes(attrs ...algnhsa.Attribute) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		kvs = append(kvs, keyValue(attr))
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+func (s span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.span.End()
+}
+
+func keyValue(attr algnhsa.Attribute) attribute.KeyValue {
+	switch v := attr.Value.(type) {
+	case string:
+		return attribute.String(attr.Key, v)
+	case bool:
+		return attribute.Bool(attr.Key, v)
+	case int:
+		return attribute.Int(attr.Key, v)
+	case int64:
+		return attribute.Int64(attr.Key, v)
+	default:
+		return attribute.String(attr.Key, fmt.Sprint(v))
+	}
+}
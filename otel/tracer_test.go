@@ -0,0 +1,45 @@
+package algnhsaotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akrylysov/algnhsa"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	asrt := assert.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(tp)
+
+	ctx, root := tracer.Start(context.Background(), algnhsa.SpanNameInvoke, algnhsa.SpanKindServer)
+	root.SetName("GET /orders/{id}")
+	root.SetAttributes(
+		algnhsa.Attribute{Key: "http.route", Value: "/orders/{id}"},
+		algnhsa.Attribute{Key: "http.response.status_code", Value: 200},
+		algnhsa.Attribute{Key: "faas.coldstart", Value: true},
+	)
+	_, child := tracer.Start(ctx, algnhsa.SpanNameHandler, algnhsa.SpanKindInternal)
+	child.End()
+	root.End()
+
+	spans := recorder.Ended()
+	asrt.Len(spans, 2)
+	asrt.Equal(algnhsa.SpanNameHandler, spans[0].Name())
+	asrt.Equal(trace.SpanKindInternal, spans[0].SpanKind())
+	asrt.Equal(spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	asrt.Equal("GET /orders/{id}", spans[1].Name())
+	asrt.Equal(trace.SpanKindServer, spans[1].SpanKind())
+	asrt.ElementsMatch([]attribute.KeyValue{
+		attribute.String("http.route", "/orders/{id}"),
+		attribute.Int("http.response.status_code", 200),
+		attribute.Bool("faas.coldstart", true),
+	}, spans[1].Attributes())
+}
@@ -0,0 +1,248 @@
+package algnhsa
+
+import (
+	"bytes"
+	"errors"
+)
+
+var errInvalidPayload = errors.New("invalid JSON payload")
+
+// jsonScanner is a minimal JSON scanner for reading a few keys from a Lambda payload
+// without decoding the rest of it, most notably the potentially large body.
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space byte.
+func (s *jsonScanner) peek() byte {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+func (s *jsonScanner) consume(c byte) error {
+	if s.peek() != c {
+		return errInvalidPayload
+	}
+	s.pos++
+	return nil
+}
+
+// readString reads a string and returns its raw contents without the quotes. Escape sequences are not decoded.
+func (s *jsonScanner) readString() ([]byte, error) {
+	if err := s.consume('"'); err != nil {
+		return nil, err
+	}
+	start := s.pos
+	for {
+		i := bytes.IndexByte(s.data[s.pos:], '"')
+		if i < 0 {
+			return nil, errInvalidPayload
+		}
+		end := s.pos + i
+		s.pos = end + 1
+		// The quote is escaped if it's preceded by an odd number of backslashes.
+		backslashes := 0
+		for j := end - 1; j >= start && s.data[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return s.data[start:end], nil
+		}
+	}
+}
+
+// skipValue skips the next value of any type.
+func (s *jsonScanner) skipValue() error {
+	switch s.peek() {
+	case '"':
+		_, err := s.readString()
+		return err
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if _, err := s.readString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+			if depth == 0 {
+				return nil
+			}
+		}
+		return errInvalidPayload
+	case 0:
+		return errInvalidPayload
+	default:
+		// Number, true, false or null.
+		start := s.pos
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				if s.pos == start {
+					return errInvalidPayload
+				}
+				return nil
+			}
+			s.pos++
+		}
+		return nil
+	}
+}
+
+// readObject calls fn for every key of an object. fn must consume the value.
+func (s *jsonScanner) readObject(fn func(key []byte) error) error {
+	if err := s.consume('{'); err != nil {
+		return err
+	}
+	if s.peek() == '}' {
+		s.pos++
+		return nil
+	}
+	for {
+		key, err := s.readString()
+		if err != nil {
+			return err
+		}
+		if err := s.consume(':'); err != nil {
+			return err
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case '}':
+			s.pos++
+			return nil
+		default:
+			return errInvalidPayload
+		}
+	}
+}
+
+// readStringOrSkip returns the contents of a string value, or skips a value of any other type.
+func (s *jsonScanner) readStringOrSkip() ([]byte, error) {
+	if s.peek() != '"' {
+		return nil, s.skipValue()
+	}
+	return s.readString()
+}
+
+// readObjectOrSkip reads an object value, or skips a value of any other type.
+func (s *jsonScanner) readObjectOrSkip(fn func(key []byte) error) error {
+	if s.peek() != '{' {
+		return s.skipValue()
+	}
+	return s.readObject(fn)
+}
+
+// findBody returns the raw body string in the payload, including the quotes.
+// It returns nil if the body is missing or is not a string.
+func findBody(payload []byte) ([]byte, error) {
+	var body []byte
+	s := &jsonScanner{data: payload}
+	err := s.readObject(func(key []byte) error {
+		if string(key) != "body" || s.peek() != '"' {
+			return s.skipValue()
+		}
+		start := s.pos
+		if _, err := s.readString(); err != nil {
+			return err
+		}
+		body = s.data[start:s.pos]
+		return nil
+	})
+	return body, err
+}
+
+// decodeEvent decodes the payload into an event of type T.
+// With Options.StreamRequestBody, the payload is decoded into W instead, which must embed T
+// and shadow its Body field with a skippedJSON field, and the raw body from the payload is returned.
+func decodeEvent[T any, W any](payload []byte, opts *Options, unwrap func(*W) T) (T, []byte, error) {
+	var event T
+	codec := opts.jsonCodec()
+	if !opts.StreamRequestBody {
+		err := codec.Unmarshal(payload, &event)
+		return event, nil, err
+	}
+	var eventWithoutBody W
+	if err := codec.Unmarshal(payload, &eventWithoutBody); err != nil {
+		return event, nil, err
+	}
+	rawBody, err := findBody(payload)
+	return unwrap(&eventWithoutBody), rawBody, err
+}
+
+// detectRequestType determines the request type from the payload in a single pass
+// by reading only the keys that distinguish the event types:
+// version, requestContext.accountId and requestContext.elb.targetGroupArn.
+func detectRequestType(payload []byte) (RequestType, error) {
+	var version, accountID, targetGroupARN []byte
+	s := &jsonScanner{data: payload}
+	err := s.readObject(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "version":
+			version, err = s.readStringOrSkip()
+		case "requestContext":
+			err = s.readObjectOrSkip(func(key []byte) error {
+				var err error
+				switch string(key) {
+				case "accountId":
+					accountID, err = s.readStringOrSkip()
+				case "elb":
+					err = s.readObjectOrSkip(func(key []byte) error {
+						var err error
+						if string(key) == "targetGroupArn" {
+							targetGroupARN, err = s.readStringOrSkip()
+						} else {
+							err = s.skipValue()
+						}
+						return err
+					})
+				default:
+					err = s.skipValue()
+				}
+				return err
+			})
+		default:
+			err = s.skipValue()
+		}
+		return err
+	})
+	if err != nil {
+		return RequestTypeAuto, err
+	}
+	switch {
+	case string(version) == "2.0":
+		return RequestTypeAPIGatewayV2, nil
+	case len(accountID) > 0:
+		return RequestTypeAPIGatewayV1, nil
+	case len(targetGroupARN) > 0:
+		return RequestTypeALB, nil
+	}
+	return RequestTypeAuto, errUnsupportedPayloadFormat
+}
@@ -0,0 +1,36 @@
+package algnhsa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectRequestType(t *testing.T) {
+	asrt := assert.New(t)
+
+	testCases := []struct {
+		payload     string
+		requestType RequestType
+		err         error
+	}{
+		{apiGatewayV1TestEvent, RequestTypeAPIGatewayV1, nil},
+		{apiGatewayV2TestEvent, RequestTypeAPIGatewayV2, nil},
+		{albTestEvent, RequestTypeALB, nil},
+		{`{"version": "1.0", "requestContext": {"accountId": "123"}}`, RequestTypeAPIGatewayV1, nil},
+		{`{"body": "\"version\": \"2.0\"\\", "requestContext": {"elb": {"targetGroupArn": "arn"}}}`, RequestTypeALB, nil},
+		{`{"version": null, "requestContext": {"accountId": null, "elb": {"targetGroupArn": "arn", "x": [1, {"y": "}"}]}}}`, RequestTypeALB, nil},
+		{`{"requestContext": {"elb": {"targetGroupArn": ""}}}`, RequestTypeAuto, errUnsupportedPayloadFormat},
+		{`{"requestContext": "accountId"}`, RequestTypeAuto, errUnsupportedPayloadFormat},
+		{`{}`, RequestTypeAuto, errUnsupportedPayloadFormat},
+		{`{"version": "2.0"`, RequestTypeAuto, errInvalidPayload},
+		{`{"body": "unterminated}`, RequestTypeAuto, errInvalidPayload},
+		{`[]`, RequestTypeAuto, errInvalidPayload},
+		{``, RequestTypeAuto, errInvalidPayload},
+	}
+	for _, tc := range testCases {
+		requestType, err := detectRequestType([]byte(tc.payload))
+		asrt.Equal(tc.err, err, tc.payload)
+		asrt.Equal(tc.requestType, requestType, tc.payload)
+	}
+}
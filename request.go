@@ -2,12 +2,9 @@ package algnhsa
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
-	"io"
 	"net/http"
 	"net/url"
-	"strings"
 )
 
 var errUnsupportedPayloadFormat = errors.New("unsupported payload format; supported formats: APIGatewayV2HTTPRequest, APIGatewayProxyRequest, ALBTargetGroupRequest")
@@ -20,53 +17,67 @@ type lambdaRequest struct {
 	RawQueryString                  string
 	Headers                         map[string]string
 	MultiValueHeaders               map[string][]string
+	PathParameters                  map[string]string
 	IsBase64Encoded                 bool
 	Body                            string
+	rawBody                         []byte
 	SourceIP                        string
 	Context                         context.Context
 	requestType                     RequestType
+	event                           interface{}
+	originSecrets                   []string // values of the OriginVerifier header removed from the event
 }
 
-func newLambdaRequest(ctx context.Context, payload []byte, opts *Options) (lambdaRequest, error) {
-	switch opts.RequestType {
-	case RequestTypeAPIGatewayV1:
-		return newAPIGatewayV1Request(ctx, payload, opts)
-	case RequestTypeAPIGatewayV2:
-		return newAPIGatewayV2Request(ctx, payload, opts)
-	case RequestTypeALB:
-		return newALBRequest(ctx, payload, opts)
+// EventFromContext extracts the Lambda event of type T from ctx.
+// T is events.APIGatewayProxyRequest, events.APIGatewayV2HTTPRequest or events.ALBTargetGroupRequest.
+// It returns false if ctx doesn't contain an event of type T.
+func EventFromContext[T any](ctx context.Context) (T, bool) {
+	for _, requestType := range []RequestType{RequestTypeAPIGatewayV2, RequestTypeAPIGatewayV1, RequestTypeALB} {
+		if event, ok := ctx.Value(requestType).(T); ok {
+			return event, true
+		}
 	}
+	var zero T
+	return zero, false
+}
 
-	// The request type wasn't specified.
-	// Try to decode the payload as APIGatewayV2HTTPRequest, fall back to APIGatewayProxyRequest, then ALBTargetGroupRequest.
-	req, err := newAPIGatewayV2Request(ctx, payload, opts)
-	if err != nil && err != errAPIGatewayV2UnexpectedRequest {
-		return lambdaRequest{}, err
-	}
-	if err == nil {
-		return req, nil
+func newLambdaRequest(ctx context.Context, payload []byte, opts *Options) (lambdaRequest, error) {
+	requestType := opts.RequestType
+	if requestType == RequestTypeAuto {
+		// The request type wasn't specified.
+		// Detect it without decoding the whole payload, then decode the payload once.
+		var err error
+		requestType, err = detectRequestType(payload)
+		if err != nil {
+			return lambdaRequest{}, err
+		}
 	}
 
-	req, err = newAPIGatewayV1Request(ctx, payload, opts)
-	if err != nil && err != errAPIGatewayV1UnexpectedRequest {
-		return lambdaRequest{}, err
+	var req lambdaRequest
+	var err error
+	switch requestType {
+	case RequestTypeAPIGatewayV1:
+		req, err = newAPIGatewayV1Request(ctx, payload, opts)
+	case RequestTypeAPIGatewayV2:
+		req, err = newAPIGatewayV2Request(ctx, payload, opts)
+	case RequestTypeALB:
+		req, err = newALBRequest(ctx, payload, opts)
+	default:
+		return lambdaRequest{}, errUnsupportedPayloadFormat
 	}
-	if err == nil {
-		return req, nil
+	if err != nil {
+		return req, err
 	}
 
-	req, err = newALBRequest(ctx, payload, opts)
-	if err != nil && err != errALBUnexpectedRequest {
-		return lambdaRequest{}, err
-	}
-	if err == nil {
-		return req, nil
+	// The header maps are shared with the event stored in the context,
+	// the secret header is removed from the event as well, so it's never exposed to the application.
+	if opts.OriginVerifier != nil {
+		req.originSecrets = opts.OriginVerifier.removeHeader(req.Headers, req.MultiValueHeaders)
 	}
-
-	return lambdaRequest{}, errUnsupportedPayloadFormat
+	return req, nil
 }
 
-func newHTTPRequest(event lambdaRequest) (*http.Request, error) {
+func newHTTPRequest(event lambdaRequest, opts *Options) (*http.Request, error) {
 	// Build request URL.
 	rawQuery := event.RawQueryString
 	if len(rawQuery) == 0 {
@@ -92,6 +103,8 @@ func newHTTPRequest(event lambdaRequest) (*http.Request, error) {
 		headers[http.CanonicalHeaderKey(k)] = vals
 	}
 
+	setAuthorizerHeaders(event.Context, headers, opts)
+
 	unescapedPath, err := url.PathUnescape(event.Path)
 	if err != nil {
 		return nil, err
@@ -102,18 +115,17 @@ func newHTTPRequest(event lambdaRequest) (*http.Request, error) {
 		RawQuery: rawQuery,
 	}
 
-	// Handle base64 encoded body.
-	var body io.Reader = strings.NewReader(event.Body)
-	if event.IsBase64Encoded {
-		body = base64.NewDecoder(base64.StdEncoding, body)
-	}
-
 	// Create a new request.
-	r, err := http.NewRequestWithContext(event.Context, event.HTTPMethod, u.String(), body)
+	r, err := http.NewRequestWithContext(event.Context, event.HTTPMethod, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
+	// Set body, handle base64 encoded body.
+	if err := setRequestBody(r, event); err != nil {
+		return nil, err
+	}
+
 	// Set remote IP address.
 	r.RemoteAddr = event.SourceIP
 
@@ -122,5 +134,12 @@ func newHTTPRequest(event lambdaRequest) (*http.Request, error) {
 
 	r.Header = headers
 
+	// Set path values matched by API Gateway.
+	if opts.SetPathValues {
+		for k, v := range event.PathParameters {
+			r.SetPathValue(k, v)
+		}
+	}
+
 	return r, nil
 }
@@ -0,0 +1,142 @@
+package algnhsa
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// RequestContext is a normalized view of the request context of API Gateway V1, API Gateway V2 and ALB events.
+// Fields that don't apply to the request type are left empty.
+type RequestContext struct {
+	// RequestID is the API Gateway request ID. It's empty for ALB requests.
+	RequestID string
+
+	// RequestType is the type of the event.
+	RequestType RequestType
+
+	// Stage is the API Gateway stage name.
+	Stage string
+
+	// APIID is the API Gateway API ID.
+	APIID string
+
+	// AccountID is the AWS account ID of the API Gateway API owner.
+	AccountID string
+
+	// TargetGroupARN is the ALB target group ARN.
+	TargetGroupARN string
+
+	// DomainName is the domain name the API Gateway API was called with.
+	DomainName string
+
+	// RequestTime is the time API Gateway received the request. It's zero for ALB requests.
+	RequestTime time.Time
+
+	// SourceIP is the IP address of the client.
+	SourceIP string
+
+	// UserAgent is the user agent of the client.
+	UserAgent string
+
+	// Authorizer is the authorizer data.
+	// For API Gateway V1 it's requestContext.authorizer as is.
+	// For API Gateway V2 it's the Lambda authorizer context, or the "claims" and "scopes" of the JWT authorizer,
+	// or the "iam" identity for IAM authorization.
+	Authorizer map[string]interface{}
+
+	// TraceHeader is the active AWS X-Ray trace header.
+	TraceHeader string
+
+	// Event is the original event, one of events.APIGatewayProxyRequest, events.APIGatewayV2HTTPRequest
+	// or events.ALBTargetGroupRequest.
+	Event interface{}
+}
+
+// RequestContextFromContext returns the normalized request context of the event in ctx.
+func RequestContextFromContext(ctx context.Context) (RequestContext, bool) {
+	var rc RequestContext
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		rc = newAPIGatewayV1RequestContext(event)
+	} else if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		rc = newAPIGatewayV2RequestContext(event)
+	} else if event, ok := ALBRequestFromContext(ctx); ok {
+		rc = newALBRequestContext(event)
+	} else {
+		return RequestContext{}, false
+	}
+	rc.TraceHeader = TraceHeaderFromContext(ctx)
+	return rc, true
+}
+
+func epochMillisToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+func newAPIGatewayV1RequestContext(event events.APIGatewayProxyRequest) RequestContext {
+	rc := event.RequestContext
+	return RequestContext{
+		RequestID:   rc.RequestID,
+		RequestType: RequestTypeAPIGatewayV1,
+		Stage:       rc.Stage,
+		APIID:       rc.APIID,
+		AccountID:   rc.AccountID,
+		DomainName:  rc.DomainName,
+		RequestTime: epochMillisToTime(rc.RequestTimeEpoch),
+		SourceIP:    rc.Identity.SourceIP,
+		UserAgent:   rc.Identity.UserAgent,
+		Authorizer:  rc.Authorizer,
+		Event:       event,
+	}
+}
+
+func newAPIGatewayV2RequestContext(event events.APIGatewayV2HTTPRequest) RequestContext {
+	rc := event.RequestContext
+	var authorizer map[string]interface{}
+	if rc.Authorizer != nil {
+		switch {
+		case rc.Authorizer.Lambda != nil:
+			authorizer = rc.Authorizer.Lambda
+		case rc.Authorizer.JWT != nil:
+			authorizer = map[string]interface{}{
+				"claims": rc.Authorizer.JWT.Claims,
+				"scopes": rc.Authorizer.JWT.Scopes,
+			}
+		case rc.Authorizer.IAM != nil:
+			authorizer = map[string]interface{}{
+				"iam": *rc.Authorizer.IAM,
+			}
+		}
+	}
+	return RequestContext{
+		RequestID:   rc.RequestID,
+		RequestType: RequestTypeAPIGatewayV2,
+		Stage:       rc.Stage,
+		APIID:       rc.APIID,
+		AccountID:   rc.AccountID,
+		DomainName:  rc.DomainName,
+		RequestTime: epochMillisToTime(rc.TimeEpoch),
+		SourceIP:    rc.HTTP.SourceIP,
+		UserAgent:   rc.HTTP.UserAgent,
+		Authorizer:  authorizer,
+		Event:       event,
+	}
+}
+
+func newALBRequestContext(event events.ALBTargetGroupRequest) RequestContext {
+	var userAgent string
+	if vals := event.MultiValueHeaders["user-agent"]; len(vals) > 0 {
+		userAgent = vals[0]
+	}
+	return RequestContext{
+		RequestType:    RequestTypeALB,
+		TargetGroupARN: event.RequestContext.ELB.TargetGroupArn,
+		SourceIP:       getALBSourceIP(event),
+		UserAgent:      userAgent,
+		Event:          event,
+	}
+}
@@ -0,0 +1,85 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// captureRequest invokes a handler with the payload and returns the HTTP request the handler received.
+func captureRequest(t *testing.T, payload string, opts *Options) *http.Request {
+	t.Helper()
+	var req *http.Request
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		req = r
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        opts,
+	}
+	_, err := lh.Invoke(context.Background(), []byte(payload))
+	assert.NoError(t, err)
+	return req
+}
+
+func requestContextFromEvent(t *testing.T, payload string) RequestContext {
+	t.Helper()
+	rc, ok := RequestContextFromContext(captureRequest(t, payload, &Options{}).Context())
+	assert.True(t, ok)
+	rc.Event = nil
+	return rc
+}
+
+func TestRequestContextAPIGatewayV1(t *testing.T) {
+	rc := requestContextFromEvent(t, apiGatewayV1TestEvent)
+	assert.Equal(t, RequestContext{
+		RequestID:   "id=",
+		RequestType: RequestTypeAPIGatewayV1,
+		Stage:       "$default",
+		APIID:       "id",
+		AccountID:   "123456789012",
+		DomainName:  "id.execute-api.us-east-1.amazonaws.com",
+		RequestTime: time.UnixMilli(1583349317135).UTC(),
+		SourceIP:    "192.0.2.1",
+		UserAgent:   "user-agent",
+		Authorizer:  map[string]interface{}{"claims": nil, "scopes": nil},
+	}, rc)
+}
+
+func TestRequestContextAPIGatewayV2(t *testing.T) {
+	rc := requestContextFromEvent(t, apiGatewayV2TestEvent)
+	assert.Equal(t, RequestContext{
+		RequestID:   "id",
+		RequestType: RequestTypeAPIGatewayV2,
+		Stage:       "$default",
+		APIID:       "api-id",
+		AccountID:   "123456789012",
+		DomainName:  "id.execute-api.us-east-1.amazonaws.com",
+		RequestTime: time.UnixMilli(1583348638390).UTC(),
+		SourceIP:    "IP",
+		UserAgent:   "agent",
+		Authorizer: map[string]interface{}{
+			"claims": map[string]string{"claim1": "value1", "claim2": "value2"},
+			"scopes": []string{"scope1", "scope2"},
+		},
+	}, rc)
+}
+
+func TestRequestContextALB(t *testing.T) {
+	rc := requestContextFromEvent(t, albTestEvent)
+	assert.Equal(t, RequestContext{
+		RequestType:    RequestTypeALB,
+		TargetGroupARN: "arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/lambda-279XGJDqGZ5rsrHC2Fjr/49e9d65c45c6791a",
+		SourceIP:       "72.12.164.125",
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/71.0.3578.98 Safari/537.36",
+		TraceHeader:    "Root=1-5c536348-3d683b8b04734faae651f476",
+	}, rc)
+}
+
+func TestRequestContextMissing(t *testing.T) {
+	_, ok := RequestContextFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,180 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func largeBodyEvent(b *testing.B, event string, body string) []byte {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(event), &m); err != nil {
+		b.Fatal(err)
+	}
+	m["body"] = body
+	m["isBase64Encoded"] = true
+	payload, err := json.Marshal(m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return payload
+}
+
+func benchmarkNewLambdaRequest(b *testing.B, event string) {
+	body := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 4<<20)))
+	payload := largeBodyEvent(b, event, body)
+	opts := &Options{}
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := newLambdaRequest(context.Background(), payload, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewLambdaRequestAPIGatewayV2(b *testing.B) {
+	benchmarkNewLambdaRequest(b, apiGatewayV2TestEvent)
+}
+
+func BenchmarkNewLambdaRequestAPIGatewayV1(b *testing.B) {
+	benchmarkNewLambdaRequest(b, apiGatewayV1TestEvent)
+}
+
+func BenchmarkNewLambdaRequestALB(b *testing.B) {
+	benchmarkNewLambdaRequest(b, albTestEvent)
+}
+
+func TestNewLambdaRequestUnsupported(t *testing.T) {
+	_, err := newLambdaRequest(context.Background(), []byte(`{"body": "{\"version\": \"2.0\"}"}`), &Options{})
+	assert.Equal(t, errUnsupportedPayloadFormat, err)
+}
+
+func BenchmarkNewLambdaRequestStreamRequestBody(b *testing.B) {
+	body := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 4<<20)))
+	payload := largeBodyEvent(b, apiGatewayV2TestEvent, body)
+	opts := &Options{StreamRequestBody: true}
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		event, err := newLambdaRequest(context.Background(), payload, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		r, err := newHTTPRequest(event, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRequestBody(t *testing.T) {
+	asrt := assert.New(t)
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+
+	for _, tc := range []struct {
+		body     string
+		isBase64 bool
+		expected string
+	}{
+		{"", false, ""},
+		{"Hello from Lambda", false, "Hello from Lambda"},
+		{"\"Hello\"\nfrom\tLambdaé", false, "\"Hello\"\nfrom\tLambdaé"},
+		{"", true, ""},
+		{"SGVsbG8gZnJvbSBMYW1iZGE=", true, "Hello from Lambda"},
+		{"SGVsbG8gZnJvbSBMYW1iZGEh", true, "Hello from Lambda!"},
+		{"SGVsbG8gZnJvbSBMYW1iZA==", true, "Hello from Lambd"},
+	} {
+		event.Body = tc.body
+		event.IsBase64Encoded = tc.isBase64
+		payload, err := json.Marshal(event)
+		asrt.NoError(err)
+
+		for _, opts := range []*Options{{}, {StreamRequestBody: true}} {
+			r := captureRequest(t, string(payload), opts)
+			asrt.Equal(int64(len(tc.expected)), r.ContentLength, tc.body)
+			body, err := io.ReadAll(r.Body)
+			asrt.NoError(err)
+			asrt.Equal(tc.expected, string(body), tc.body)
+
+			// GetBody returns a fresh copy of the body.
+			rc, err := r.GetBody()
+			asrt.NoError(err)
+			body, err = io.ReadAll(rc)
+			asrt.NoError(err)
+			asrt.Equal(tc.expected, string(body), tc.body)
+		}
+	}
+}
+
+func TestStreamRequestBody(t *testing.T) {
+	asrt := assert.New(t)
+
+	for _, tc := range []struct {
+		event    string
+		dump     func([]byte, Options) (RequestDebugDump, error)
+		expected RequestDebugDump
+	}{
+		{apiGatewayV1TestEvent, dumpAPIGatewayV1, expectedApiGatewayV1Dump},
+		{apiGatewayV2TestEvent, dumpAPIGatewayV2, expectedApiGatewayV2Dump},
+		{albTestEvent, dumpALB, expectedALBDump},
+	} {
+		dump, err := tc.dump([]byte(tc.event), Options{StreamRequestBody: true})
+		asrt.NoError(err)
+		asrt.Equal(tc.expected, dump)
+	}
+
+	r := captureRequest(t, apiGatewayV2TestEvent, &Options{StreamRequestBody: true})
+	event, ok := APIGatewayV2RequestFromContext(r.Context())
+	asrt.True(ok)
+	asrt.Empty(event.Body)
+}
+
+func TestEventFromContext(t *testing.T) {
+	asrt := assert.New(t)
+	r := captureRequest(t, albTestEvent, &Options{})
+
+	event, ok := EventFromContext[events.ALBTargetGroupRequest](r.Context())
+	asrt.True(ok)
+	asrt.Equal("GET", event.HTTPMethod)
+
+	_, ok = EventFromContext[events.APIGatewayV2HTTPRequest](r.Context())
+	asrt.False(ok)
+
+	anyEvent, ok := EventFromContext[interface{}](r.Context())
+	asrt.True(ok)
+	asrt.Equal(event, anyEvent)
+
+	_, ok = EventFromContext[events.ALBTargetGroupRequest](context.Background())
+	asrt.False(ok)
+}
+
+type tenantKey struct{}
+
+func TestRequestContextOption(t *testing.T) {
+	asrt := assert.New(t)
+	opts := &Options{
+		RequestContext: func(ctx context.Context, event interface{}) context.Context {
+			v2, ok := event.(events.APIGatewayV2HTTPRequest)
+			asrt.True(ok)
+			return context.WithValue(ctx, tenantKey{}, v2.RequestContext.AccountID)
+		},
+	}
+	r := captureRequest(t, apiGatewayV2TestEvent, opts)
+	asrt.Equal("123456789012", r.Context().Value(tenantKey{}))
+	_, ok := APIGatewayV2RequestFromContext(r.Context())
+	asrt.True(ok)
+}
@@ -3,7 +3,6 @@ package algnhsa
 import (
 	"encoding/base64"
 	"net/http"
-	"net/http/httptest"
 )
 
 const (
@@ -13,27 +12,65 @@ const (
 
 var canonicalSetCookieHeaderKey = http.CanonicalHeaderKey("Set-Cookie")
 
-// lambdaResponse is a combined lambda response.
+// LambdaResponse is a combined lambda response.
 // It contains common fields from APIGatewayProxyResponse, APIGatewayV2HTTPResponse and ALBTargetGroupResponse.
-type lambdaResponse struct {
+// Only the fields supported by the request type are set.
+type LambdaResponse struct {
 	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
 	Headers           map[string]string   `json:"headers,omitempty"`
 	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
 	Cookies           []string            `json:"cookies,omitempty"`
 	Body              string              `json:"body"`
 	IsBase64Encoded   bool                `json:"isBase64Encoded,omitempty"`
+
+	// binaryBody is the body to be base64 encoded into Body. It's encoded when the response is marshaled,
+	// or before the response is passed to code that can read Body.
+	binaryBody []byte
+}
+
+// encodeBody base64 encodes the binary body into Body.
+func (resp *LambdaResponse) encodeBody() {
+	if resp.binaryBody != nil {
+		resp.Body = base64.StdEncoding.EncodeToString(resp.binaryBody)
+		resp.binaryBody = nil
+	}
+}
+
+// decodedBody returns the decoded response body.
+func (resp LambdaResponse) decodedBody() string {
+	if resp.binaryBody != nil {
+		return string(resp.binaryBody)
+	}
+	if resp.IsBase64Encoded {
+		if body, err := base64.StdEncoding.DecodeString(resp.Body); err == nil {
+			return string(body)
+		}
+	}
+	return resp.Body
+}
+
+// bodySize returns the size of the decoded response body.
+func (resp LambdaResponse) bodySize() int {
+	if resp.binaryBody != nil {
+		return len(resp.binaryBody)
+	}
+	if !resp.IsBase64Encoded {
+		return len(resp.Body)
+	}
+	return base64StringDecodedLen(resp.Body)
 }
 
-func newLambdaResponse(w *httptest.ResponseRecorder, opts *Options, requestType RequestType) (lambdaResponse, error) {
+func newLambdaResponse(w *responseWriter, opts *Options, requestType RequestType) (LambdaResponse, error) {
 	result := w.Result()
 
-	var resp lambdaResponse
+	var resp LambdaResponse
 	var err error
 	switch requestType {
 	case RequestTypeAPIGatewayV1:
-		resp, err = newAPIGatewayV1Response(result)
+		resp, err = newAPIGatewayV1Response(result, &w.options)
 	case RequestTypeALB:
-		resp, err = newALBResponse(result)
+		resp, err = newALBResponse(result, &w.options)
 	case RequestTypeAPIGatewayV2:
 		resp, err = newAPIGatewayV2Response(result)
 	}
@@ -46,11 +83,12 @@ func newLambdaResponse(w *httptest.ResponseRecorder, opts *Options, requestType
 	// Set body.
 	contentType := result.Header.Get("Content-Type")
 	contentEncoding := result.Header.Get("Content-Encoding")
-	if opts.binaryContentTypes.contains(acceptAllContentType) ||
+	if w.options.Base64 ||
+		opts.binaryContentTypes.contains(acceptAllContentType) ||
 		opts.binaryContentTypes.contains(contentType) ||
 		opts.binaryContentEncodings.contains(acceptAllContentEncoding) ||
 		opts.binaryContentEncodings.contains(contentEncoding) {
-		resp.Body = base64.StdEncoding.EncodeToString(w.Body.Bytes())
+		resp.binaryBody = w.Body.Bytes()
 		resp.IsBase64Encoded = true
 	} else {
 		resp.Body = w.Body.String()
@@ -0,0 +1,51 @@
+package algnhsa
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// ResponseOptions are per-response settings for Lambda response fields that can't be expressed
+// through http.ResponseWriter. Get them with ResponseOptionsFromWriter.
+type ResponseOptions struct {
+	// StatusDescription sets the ALB response status description, e.g. "200 OK".
+	StatusDescription string
+
+	// Base64 forces base64 encoding of the response body,
+	// regardless of Options.BinaryContentTypes and Options.BinaryContentEncodings.
+	Base64 bool
+
+	// SingleValueHeaders makes API Gateway V1 return the response headers in the headers field
+	// instead of multiValueHeaders. Multiple values of a header are joined with a comma.
+	// Set-Cookie headers are always returned in multiValueHeaders.
+	SingleValueHeaders bool
+}
+
+// responseWriter is the http.ResponseWriter passed to the handler.
+type responseWriter struct {
+	*httptest.ResponseRecorder
+	options ResponseOptions
+
+	// requestBodyTooLarge is set when the handler read a decompressed request body exceeding the limit.
+	requestBodyTooLarge bool
+}
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{ResponseRecorder: httptest.NewRecorder()}
+}
+
+// ResponseOptionsFromWriter returns the options of the Lambda response written by w.
+// Changes to the returned options apply to the response.
+// Response writers wrapped by middleware are supported if they implement Unwrap() http.ResponseWriter.
+func ResponseOptionsFromWriter(w http.ResponseWriter) (*ResponseOptions, bool) {
+	for {
+		switch t := w.(type) {
+		case *responseWriter:
+			return &t.options, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
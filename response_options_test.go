@@ -0,0 +1,106 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+type wrappedResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrappedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func invokeWithResponseOptions(t *testing.T, event string, setOptions func(opts *ResponseOptions)) []byte {
+	t.Helper()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		respOpts, ok := ResponseOptionsFromWriter(wrappedResponseWriter{w})
+		assert.True(t, ok)
+		setOptions(respOpts)
+		w.Header().Add("X-Bar", "2")
+		w.Header().Add("X-Bar", "3")
+		io.WriteString(w, "Hello from Lambda!")
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), []byte(event))
+	assert.NoError(t, err)
+	return responseBytes
+}
+
+func TestResponseOptionsALBStatusDescription(t *testing.T) {
+	asrt := assert.New(t)
+
+	responseBytes := invokeWithResponseOptions(t, albTestEvent, func(opts *ResponseOptions) {
+		opts.StatusDescription = "200 OK"
+	})
+	var r events.ALBTargetGroupResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal("200 OK", r.StatusDescription)
+	asrt.Equal([]string{"2", "3"}, r.MultiValueHeaders["X-Bar"])
+}
+
+func TestResponseOptionsBase64(t *testing.T) {
+	asrt := assert.New(t)
+
+	responseBytes := invokeWithResponseOptions(t, apiGatewayV2TestEvent, func(opts *ResponseOptions) {
+		opts.Base64 = true
+	})
+	var r events.APIGatewayV2HTTPResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.True(r.IsBase64Encoded)
+	asrt.Equal("SGVsbG8gZnJvbSBMYW1iZGEh", r.Body)
+}
+
+func TestResponseOptionsSingleValueHeaders(t *testing.T) {
+	asrt := assert.New(t)
+
+	responseBytes := invokeWithResponseOptions(t, apiGatewayV1TestEvent, func(opts *ResponseOptions) {
+		opts.SingleValueHeaders = true
+	})
+	var r events.APIGatewayProxyResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal("2,3", r.Headers["X-Bar"])
+	asrt.Nil(r.MultiValueHeaders)
+	asrt.NotContains(string(responseBytes), "statusDescription")
+}
+
+func TestResponseOptionsSingleValueHeadersCookies(t *testing.T) {
+	asrt := assert.New(t)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		respOpts, _ := ResponseOptionsFromWriter(w)
+		respOpts.SingleValueHeaders = true
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "1", Expires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)})
+		http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+		w.Header().Set("X-Foo", "1")
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), []byte(apiGatewayV1TestEvent))
+	asrt.NoError(err)
+	var r events.APIGatewayProxyResponse
+	asrt.NoError(json.Unmarshal(responseBytes, &r))
+	asrt.Equal(map[string]string{"X-Foo": "1"}, r.Headers)
+	asrt.Equal(map[string][]string{
+		"Set-Cookie": {"a=1; Expires=Wed, 02 Jan 2030 03:04:05 GMT", "b=2"},
+	}, r.MultiValueHeaders)
+}
+
+func TestResponseOptionsFromWriterUnknown(t *testing.T) {
+	_, ok := ResponseOptionsFromWriter(wrappedResponseWriter{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,133 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const anyMethod = "ANY"
+
+// defaultRouteKey is the API Gateway V2 route key of the catch-all route.
+const defaultRouteKey = "$default"
+
+// RouteFromContext returns the API Gateway route the request was matched against.
+// For API Gateway V1 it's the resource path template, e.g. "/orders/{id}".
+// For API Gateway V2 it's the route key, e.g. "GET /orders/{id}" or "$default".
+// It returns false for ALB requests.
+func RouteFromContext(ctx context.Context) (string, bool) {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		return event.Resource, true
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		return event.RouteKey, true
+	}
+	return "", false
+}
+
+// routeMatchFromContext returns the method, the resource path template and the path parameters
+// of the API Gateway route the request was matched against.
+func routeMatchFromContext(ctx context.Context) (method string, resource string, params map[string]string, ok bool) {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		return event.HTTPMethod, event.Resource, event.PathParameters, true
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		method, resource, found := strings.Cut(event.RouteKey, " ")
+		if !found {
+			// $default route.
+			return "", event.RouteKey, event.PathParameters, true
+		}
+		return method, resource, event.PathParameters, true
+	}
+	return "", "", nil, false
+}
+
+// RouteMux is an HTTP request multiplexer that dispatches requests using the route already matched by API Gateway.
+// Routes are registered with API Gateway route keys, e.g. "GET /orders/{id}" or "ANY /{proxy+}".
+// For API Gateway V1 the route key is built from the event httpMethod and resource.
+// API Gateway path parameters are available through http.Request.PathValue in the registered handlers.
+// Requests served by the local HTTP server started by ListenAndServe outside of Lambda have no route,
+// they are always handled by Fallback.
+type RouteMux struct {
+	mu     sync.RWMutex
+	routes map[string]http.Handler
+
+	// Fallback handles requests that don't match any registered route,
+	// including ALB requests and requests that didn't come from API Gateway.
+	// By default, http.NotFoundHandler is used.
+	Fallback http.Handler
+}
+
+// NewRouteMux returns a new RouteMux.
+func NewRouteMux() *RouteMux {
+	return &RouteMux{routes: make(map[string]http.Handler)}
+}
+
+// Handle registers the handler for the given route key.
+// If a handler already exists for the route key, or the handler is nil, Handle panics.
+func (mux *RouteMux) Handle(routeKey string, handler http.Handler) {
+	if routeKey == "" {
+		panic("algnhsa: invalid route key")
+	}
+	if handler == nil {
+		panic("algnhsa: nil handler")
+	}
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if _, exist := mux.routes[routeKey]; exist {
+		panic("algnhsa: multiple registrations for " + routeKey)
+	}
+	if mux.routes == nil {
+		mux.routes = make(map[string]http.Handler)
+	}
+	mux.routes[routeKey] = handler
+}
+
+// HandleFunc registers the handler function for the given route key.
+// If a handler already exists for the route key, or the handler is nil, HandleFunc panics.
+func (mux *RouteMux) HandleFunc(routeKey string, handler func(http.ResponseWriter, *http.Request)) {
+	if handler == nil {
+		panic("algnhsa: nil handler")
+	}
+	mux.Handle(routeKey, http.HandlerFunc(handler))
+}
+
+func (mux *RouteMux) match(ctx context.Context) (http.Handler, map[string]string) {
+	method, resource, params, ok := routeMatchFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+	if method == "" {
+		if h, ok := mux.routes[resource]; ok {
+			return h, params
+		}
+		return nil, nil
+	}
+	if h, ok := mux.routes[method+" "+resource]; ok {
+		return h, params
+	}
+	if h, ok := mux.routes[anyMethod+" "+resource]; ok {
+		return h, params
+	}
+	return nil, nil
+}
+
+// ServeHTTP dispatches the request to the handler registered for the API Gateway route.
+func (mux *RouteMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, params := mux.match(r.Context())
+	if h == nil {
+		h = mux.Fallback
+		if h == nil {
+			h = http.NotFoundHandler()
+		}
+		h.ServeHTTP(w, r)
+		return
+	}
+	for k, v := range params {
+		r.SetPathValue(k, v)
+	}
+	h.ServeHTTP(w, r)
+}
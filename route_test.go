@@ -0,0 +1,122 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func invokeRouteMux(t *testing.T, mux *RouteMux, payload []byte) LambdaResponse {
+	t.Helper()
+	lh := lambdaHandler{
+		httpHandler: mux,
+		opts:        &Options{},
+	}
+	responseBytes, err := lh.Invoke(context.Background(), payload)
+	assert.NoError(t, err)
+	var r LambdaResponse
+	assert.NoError(t, json.Unmarshal(responseBytes, &r))
+	return r
+}
+
+func newTestRouteMux() *RouteMux {
+	mux := NewRouteMux()
+	mux.HandleFunc("POST /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "post "+r.PathValue("id"))
+	})
+	mux.HandleFunc("ANY /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "any "+r.PathValue("id"))
+	})
+	mux.HandleFunc("$default", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "default")
+	})
+	return mux
+}
+
+func TestRouteMuxAPIGatewayV2(t *testing.T) {
+	asrt := assert.New(t)
+	mux := newTestRouteMux()
+
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.PathParameters = map[string]string{"id": "42"}
+
+	for routeKey, expected := range map[string]string{
+		"POST /orders/{id}": "post 42",
+		"ANY /items/{id}":   "any 42",
+		"$default":          "default",
+	} {
+		event.RouteKey = routeKey
+		payload, err := json.Marshal(event)
+		asrt.NoError(err)
+		r := invokeRouteMux(t, mux, payload)
+		asrt.Equal(200, r.StatusCode)
+		asrt.Equal(expected, r.Body)
+	}
+
+	event.RouteKey = "GET /unknown"
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+	r := invokeRouteMux(t, mux, payload)
+	asrt.Equal(404, r.StatusCode)
+}
+
+func TestRouteMuxAPIGatewayV1(t *testing.T) {
+	asrt := assert.New(t)
+	mux := newTestRouteMux()
+
+	event := events.APIGatewayProxyRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV1TestEvent), &event))
+	event.HTTPMethod = "POST"
+	event.Resource = "/orders/{id}"
+	event.PathParameters = map[string]string{"id": "42"}
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+	r := invokeRouteMux(t, mux, payload)
+	asrt.Equal(200, r.StatusCode)
+	asrt.Equal("post 42", r.Body)
+
+	event.HTTPMethod = "GET"
+	payload, err = json.Marshal(event)
+	asrt.NoError(err)
+	r = invokeRouteMux(t, mux, payload)
+	asrt.Equal(404, r.StatusCode)
+}
+
+func TestRouteMuxFallback(t *testing.T) {
+	asrt := assert.New(t)
+	mux := newTestRouteMux()
+	mux.Fallback = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "fallback")
+	})
+
+	r := invokeRouteMux(t, mux, []byte(albTestEvent))
+	asrt.Equal(200, r.StatusCode)
+	asrt.Equal("fallback", r.Body)
+}
+
+func TestRouteMuxHandlePanics(t *testing.T) {
+	mux := NewRouteMux()
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("GET /orders", h)
+	assert.PanicsWithValue(t, "algnhsa: multiple registrations for GET /orders", func() {
+		mux.Handle("GET /orders", h)
+	})
+	assert.PanicsWithValue(t, "algnhsa: nil handler", func() {
+		mux.Handle("POST /orders", nil)
+	})
+	assert.PanicsWithValue(t, "algnhsa: nil handler", func() {
+		mux.HandleFunc("POST /orders", nil)
+	})
+	assert.PanicsWithValue(t, "algnhsa: invalid route key", func() {
+		mux.Handle("", h)
+	})
+	assert.NotPanics(t, func() {
+		mux.Handle("POST /orders", h)
+	})
+}
@@ -0,0 +1,38 @@
+package algnhsa
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// RegisterOnShutdown registers a function to call when the function instance is shut down.
+// ListenAndServe enables SIGTERM handling if any functions are registered. On SIGTERM, the base context
+// of invocations is canceled, and the registered functions are called in order.
+// The base context isn't canceled if it's replaced with lambda.WithContext in LambdaOptions.
+// RegisterOnShutdown must be called before ListenAndServe.
+func (opts *Options) RegisterOnShutdown(f func()) {
+	opts.onShutdown = append(opts.onShutdown, f)
+}
+
+// shutdownContext returns the base context, and the function that cancels it and calls the registered functions.
+func (opts *Options) shutdownContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	shutdown := func() {
+		cancel()
+		for _, f := range opts.onShutdown {
+			f()
+		}
+	}
+	return ctx, shutdown
+}
+
+// lambdaOptions returns the options for lambda.StartWithOptions.
+func (opts *Options) lambdaOptions() []lambda.Option {
+	var lambdaOpts []lambda.Option
+	if len(opts.onShutdown) > 0 {
+		ctx, shutdown := opts.shutdownContext()
+		lambdaOpts = append(lambdaOpts, lambda.WithContext(ctx), lambda.WithEnableSIGTERM(shutdown))
+	}
+	return append(lambdaOpts, opts.LambdaOptions...)
+}
@@ -0,0 +1,31 @@
+package algnhsa
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownContext(t *testing.T) {
+	asrt := assert.New(t)
+	opts := &Options{}
+	var calls []int
+	opts.RegisterOnShutdown(func() { calls = append(calls, 1) })
+	opts.RegisterOnShutdown(func() { calls = append(calls, 2) })
+
+	ctx, shutdown := opts.shutdownContext()
+	asrt.NoError(ctx.Err())
+	shutdown()
+	asrt.Error(ctx.Err())
+	asrt.Equal([]int{1, 2}, calls)
+}
+
+func TestLambdaOptions(t *testing.T) {
+	asrt := assert.New(t)
+	opts := &Options{LambdaOptions: []lambda.Option{lambda.WithSetEscapeHTML(false)}}
+	asrt.Len(opts.lambdaOptions(), 1)
+
+	opts.RegisterOnShutdown(func() {})
+	asrt.Len(opts.lambdaOptions(), 3)
+}
@@ -0,0 +1,49 @@
+package algnhsa
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrSourceNotAllowed is returned by the Lambda handler when the event source is not in Options.AllowedSources.
+var ErrSourceNotAllowed = errors.New("event source is not allowed")
+
+// SourceAllowList restricts the event sources accepted by the Lambda handler.
+// Empty lists don't restrict anything.
+// APIIDs, AccountIDs and DomainNames apply to API Gateway events, TargetGroupARNs applies to ALB events.
+// Use RequestTypes to reject other event types.
+type SourceAllowList struct {
+	// RequestTypes are the allowed request types.
+	RequestTypes []RequestType
+
+	// APIIDs are the allowed API Gateway API IDs.
+	APIIDs []string
+
+	// AccountIDs are the allowed AWS account IDs of API Gateway APIs.
+	AccountIDs []string
+
+	// DomainNames are the allowed API Gateway domain names.
+	DomainNames []string
+
+	// TargetGroupARNs are the allowed ALB target group ARNs.
+	TargetGroupARNs []string
+}
+
+func allowed(list []string, v string) bool {
+	return len(list) == 0 || slices.Contains(list, v)
+}
+
+func (l *SourceAllowList) allows(rc RequestContext) bool {
+	if len(l.RequestTypes) > 0 && !slices.Contains(l.RequestTypes, rc.RequestType) {
+		return false
+	}
+	switch rc.RequestType {
+	case RequestTypeAPIGatewayV1, RequestTypeAPIGatewayV2:
+		return allowed(l.APIIDs, rc.APIID) &&
+			allowed(l.AccountIDs, rc.AccountID) &&
+			allowed(l.DomainNames, rc.DomainName)
+	case RequestTypeALB:
+		return allowed(l.TargetGroupARNs, rc.TargetGroupARN)
+	}
+	return false
+}
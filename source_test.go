@@ -0,0 +1,43 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedSources(t *testing.T) {
+	asrt := assert.New(t)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	testCases := []struct {
+		allowList *SourceAllowList
+		event     string
+		allowed   bool
+	}{
+		{&SourceAllowList{}, apiGatewayV1TestEvent, true},
+		{&SourceAllowList{APIIDs: []string{"api-id"}}, apiGatewayV2TestEvent, true},
+		{&SourceAllowList{APIIDs: []string{"other-api-id"}}, apiGatewayV2TestEvent, false},
+		{&SourceAllowList{AccountIDs: []string{"123456789012"}}, apiGatewayV1TestEvent, true},
+		{&SourceAllowList{AccountIDs: []string{"210987654321"}}, apiGatewayV1TestEvent, false},
+		{&SourceAllowList{DomainNames: []string{"example.com"}}, apiGatewayV2TestEvent, false},
+		{&SourceAllowList{RequestTypes: []RequestType{RequestTypeALB}}, apiGatewayV2TestEvent, false},
+		{&SourceAllowList{RequestTypes: []RequestType{RequestTypeALB}}, albTestEvent, true},
+		{&SourceAllowList{TargetGroupARNs: []string{"arn"}}, albTestEvent, false},
+		{&SourceAllowList{TargetGroupARNs: []string{"arn"}}, apiGatewayV1TestEvent, true},
+	}
+	for i, tc := range testCases {
+		lh := lambdaHandler{
+			httpHandler: http.HandlerFunc(handler),
+			opts:        &Options{AllowedSources: tc.allowList},
+		}
+		_, err := lh.Invoke(context.Background(), []byte(tc.event))
+		if tc.allowed {
+			asrt.NoError(err, i)
+		} else {
+			asrt.ErrorIs(err, ErrSourceNotAllowed, i)
+		}
+	}
+}
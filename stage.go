@@ -0,0 +1,47 @@
+package algnhsa
+
+import (
+	"context"
+	"strings"
+)
+
+// defaultStage is the name of the API Gateway V2 stage that is served from the base URL.
+const defaultStage = "$default"
+
+// isDefaultEndpoint reports whether the request was made to the default execute-api endpoint,
+// whose domain prefix is the API ID, rather than to a custom domain.
+func isDefaultEndpoint(domainPrefix string, apiID string) bool {
+	return apiID != "" && domainPrefix == apiID
+}
+
+// stripStage removes the "/stage" prefix from p.
+func stripStage(p string, stage string) string {
+	if stage == "" || stage == defaultStage {
+		return p
+	}
+	prefix := "/" + stage
+	if !strings.HasPrefix(p, prefix) {
+		return p
+	}
+	rest := p[len(prefix):]
+	if rest == "" {
+		return "/"
+	}
+	if rest[0] != '/' {
+		// The path only shares a prefix with the stage name, e.g. "/production" for the "prod" stage.
+		return p
+	}
+	return rest
+}
+
+// StageFromContext returns the API Gateway stage name of the request.
+// It returns false for ALB requests.
+func StageFromContext(ctx context.Context) (string, bool) {
+	if event, ok := APIGatewayV1RequestFromContext(ctx); ok {
+		return event.RequestContext.Stage, true
+	}
+	if event, ok := APIGatewayV2RequestFromContext(ctx); ok {
+		return event.RequestContext.Stage, true
+	}
+	return "", false
+}
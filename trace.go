@@ -0,0 +1,105 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+/*
+AWS Documentation:
+
+- https://docs.aws.amazon.com/xray/latest/devguide/xray-concepts.html#xray-concepts-tracingheader
+- https://www.w3.org/TR/trace-context/#traceparent-header
+*/
+
+const (
+	xrayTraceHeader        = "X-Amzn-Trace-Id"
+	traceparentHeader      = "Traceparent"
+	lambdaTraceContextKey  = "x-amzn-trace-id"
+	lambdaTraceEnvVariable = "_X_AMZN_TRACE_ID"
+)
+
+// activeTraceHeader returns the X-Ray trace header of the Lambda invocation,
+// falling back to the trace header forwarded by API Gateway or ALB.
+func activeTraceHeader(ctx context.Context, header http.Header) string {
+	if v, ok := ctx.Value(lambdaTraceContextKey).(string); ok && v != "" {
+		return v
+	}
+	if v := os.Getenv(lambdaTraceEnvVariable); v != "" {
+		return v
+	}
+	return header.Get(xrayTraceHeader)
+}
+
+// TraceHeaderFromContext returns the active AWS X-Ray trace header,
+// e.g. "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+func TraceHeaderFromContext(ctx context.Context) string {
+	if state, ok := ctx.Value(invocationContextKey).(*invocationState); ok && state.traceHeader != "" {
+		return state.traceHeader
+	}
+	return activeTraceHeader(ctx, nil)
+}
+
+func isLowerHex(s string) bool {
+	nonZero := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+		if c != '0' {
+			nonZero = true
+		}
+	}
+	return nonZero
+}
+
+// xrayToTraceparent converts an X-Ray trace header to a W3C traceparent header.
+func xrayToTraceparent(xray string) (string, bool) {
+	var root, parent string
+	sampled := "00"
+	for _, part := range strings.Split(xray, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "Root":
+			root = v
+		case "Parent":
+			parent = v
+		case "Sampled":
+			if v == "1" {
+				sampled = "01"
+			}
+		}
+	}
+	// Root is "1-" followed by 8 hex digits of the epoch time, "-" and 24 random hex digits.
+	if len(root) != 35 || root[:2] != "1-" || root[10] != '-' {
+		return "", false
+	}
+	traceID := root[2:10] + root[11:]
+	if !isLowerHex(traceID) || len(parent) != 16 || !isLowerHex(parent) {
+		return "", false
+	}
+	return "00-" + traceID + "-" + parent + "-" + sampled, true
+}
+
+// propagateTrace records the active trace header and adds it to the request
+// as X-Amzn-Trace-Id and traceparent headers when they are missing.
+func propagateTrace(r *http.Request, opts *Options) {
+	traceHeader := activeTraceHeader(r.Context(), r.Header)
+	if state, ok := r.Context().Value(invocationContextKey).(*invocationState); ok {
+		state.traceHeader = traceHeader
+	}
+	if !opts.PropagateTrace || traceHeader == "" {
+		return
+	}
+	if r.Header.Get(xrayTraceHeader) == "" {
+		r.Header.Set(xrayTraceHeader, traceHeader)
+	}
+	if r.Header.Get(traceparentHeader) == "" {
+		if traceparent, ok := xrayToTraceparent(traceHeader); ok {
+			r.Header.Set(traceparentHeader, traceparent)
+		}
+	}
+}
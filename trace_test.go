@@ -0,0 +1,67 @@
+package algnhsa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceHeader = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+
+func TestXrayToTraceparent(t *testing.T) {
+	asrt := assert.New(t)
+
+	traceparent, ok := xrayToTraceparent(testTraceHeader)
+	asrt.True(ok)
+	asrt.Equal("00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01", traceparent)
+
+	traceparent, ok = xrayToTraceparent("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0")
+	asrt.True(ok)
+	asrt.Equal("00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00", traceparent)
+
+	for _, h := range []string{
+		"",
+		"Root=1-5759e988-bd862e3fe1be46a994272793",
+		"Root=1-5759e988-bd862e3fe1be46a99427279;Parent=53995c3f42cd8ad8",
+		"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=0000000000000000",
+		"Root=1-5759e988-BD862E3FE1BE46A994272793;Parent=53995c3f42cd8ad8",
+	} {
+		_, ok := xrayToTraceparent(h)
+		asrt.False(ok, h)
+	}
+}
+
+func TestPropagateTrace(t *testing.T) {
+	asrt := assert.New(t)
+
+	ctx := context.WithValue(context.Background(), lambdaTraceContextKey, testTraceHeader)
+	var traceHeader, xrayHeader, traceparent string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		traceHeader = TraceHeaderFromContext(r.Context())
+		xrayHeader = r.Header.Get(xrayTraceHeader)
+		traceparent = r.Header.Get(traceparentHeader)
+	}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(handler),
+		opts:        &Options{PropagateTrace: true},
+	}
+	_, err := lh.Invoke(ctx, []byte(apiGatewayV2TestEvent))
+	asrt.NoError(err)
+	asrt.Equal(testTraceHeader, traceHeader)
+	asrt.Equal(testTraceHeader, xrayHeader)
+	asrt.Equal("00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01", traceparent)
+
+	// Existing traceparent headers are preserved.
+	event := events.APIGatewayV2HTTPRequest{}
+	asrt.NoError(json.Unmarshal([]byte(apiGatewayV2TestEvent), &event))
+	event.Headers["traceparent"] = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	payload, err := json.Marshal(event)
+	asrt.NoError(err)
+	_, err = lh.Invoke(ctx, payload)
+	asrt.NoError(err)
+	asrt.Equal("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", traceparent)
+}
@@ -0,0 +1,90 @@
+package algnhsa
+
+import (
+	"context"
+)
+
+// SpanKind is the role of a span in a trace.
+type SpanKind int
+
+const (
+	// SpanKindInternal is an adapter operation within the invocation.
+	SpanKindInternal SpanKind = iota
+	// SpanKindServer is the invocation serving the HTTP request.
+	SpanKindServer
+)
+
+// Span names used by the adapter.
+const (
+	SpanNameInvoke        = "algnhsa.Invoke"
+	SpanNameDecodeEvent   = "algnhsa.DecodeEvent"
+	SpanNameBuildRequest  = "algnhsa.BuildRequest"
+	SpanNameHandler       = "algnhsa.Handler"
+	SpanNameBuildResponse = "algnhsa.BuildResponse"
+	SpanNameEncode        = "algnhsa.EncodeResponse"
+)
+
+// Attribute is a span attribute. Value is a string, bool, int or int64.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer creates spans for the stages of a Lambda invocation.
+// The invocation span is started with SpanKindServer, the adapter stages with SpanKindInternal.
+// The github.com/akrylysov/algnhsa/otel module implements Tracer using OpenTelemetry.
+type Tracer interface {
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SetName(name string)
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetName(string)             {}
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// endSpan records err, if any, and ends the span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// requestAttributes returns the FaaS and HTTP semantic convention attributes of the request,
+// and the span name for the invocation span.
+func requestAttributes(event lambdaRequest) (string, []Attribute) {
+	attrs := []Attribute{
+		{Key: "faas.trigger", Value: "http"},
+		{Key: "http.request.method", Value: event.HTTPMethod},
+		{Key: "url.path", Value: event.Path},
+		{Key: "algnhsa.request_type", Value: event.requestType.String()},
+	}
+	name := event.HTTPMethod
+	if _, route, _, ok := routeMatchFromContext(event.Context); ok && route != defaultRouteKey {
+		attrs = append(attrs, Attribute{Key: "http.route", Value: route})
+		name += " " + route
+	}
+	if inv, ok := InvocationFromContext(event.Context); ok {
+		attrs = append(attrs, Attribute{Key: "faas.coldstart", Value: inv.ColdStart})
+		if id := inv.LambdaRequestID(); id != "" {
+			attrs = append(attrs, Attribute{Key: "faas.invocation_id", Value: id})
+		}
+	}
+	return name, attrs
+}
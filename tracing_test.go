@@ -0,0 +1,87 @@
+package algnhsa
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSpan struct {
+	name  string
+	kind  SpanKind
+	attrs map[string]interface{}
+	ended bool
+}
+
+func (s *testSpan) SetName(name string) { s.name = name }
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) { s.attrs["error"] = err }
+
+func (s *testSpan) End() { s.ended = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	span := &testSpan{name: name, kind: kind, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestTracer(t *testing.T) {
+	asrt := assert.New(t)
+
+	tracer := &testTracer{}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}),
+		opts: &Options{Tracer: tracer},
+	}
+	_, err := lh.Invoke(context.Background(), []byte(apiGatewayV1TestEvent))
+	asrt.NoError(err)
+
+	var names []string
+	for _, span := range tracer.spans {
+		names = append(names, span.name)
+		asrt.True(span.ended, span.name)
+	}
+	asrt.Equal([]string{
+		"GET /my/path",
+		SpanNameDecodeEvent,
+		SpanNameBuildRequest,
+		SpanNameHandler,
+		SpanNameBuildResponse,
+		SpanNameEncode,
+	}, names)
+
+	invokeSpan := tracer.spans[0]
+	asrt.Equal(SpanKindServer, invokeSpan.kind)
+	asrt.Equal("http", invokeSpan.attrs["faas.trigger"])
+	asrt.Equal("GET", invokeSpan.attrs["http.request.method"])
+	asrt.Equal("/my/path", invokeSpan.attrs["http.route"])
+	asrt.Equal("APIGatewayV1", invokeSpan.attrs["algnhsa.request_type"])
+	asrt.Equal(http.StatusCreated, invokeSpan.attrs["http.response.status_code"])
+	asrt.Contains(invokeSpan.attrs, "faas.coldstart")
+}
+
+func TestTracerError(t *testing.T) {
+	tracer := &testTracer{}
+	lh := lambdaHandler{
+		httpHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		opts:        &Options{Tracer: tracer},
+	}
+	_, err := lh.Invoke(context.Background(), []byte("{}"))
+	assert.Error(t, err)
+	assert.Equal(t, err, tracer.spans[0].attrs["error"])
+	assert.Equal(t, err, tracer.spans[1].attrs["error"])
+}